@@ -0,0 +1,172 @@
+package mat
+
+// Add returns m + other as a new matrix. Panics if shapes differ.
+func (m *M) Add(other *M) *M {
+	dst := New(m.rows, m.cols)
+	m.AddTo(other, dst)
+	return dst
+}
+
+// AddTo stores m + other in dst, which can be m or other to work in
+// place. Panics if shapes differ.
+func (m *M) AddTo(other, dst *M) {
+	zip(m, other, dst, "add", func(a, b float64) float64 { return a + b })
+}
+
+// Sub returns m - other as a new matrix. Panics if shapes differ.
+func (m *M) Sub(other *M) *M {
+	dst := New(m.rows, m.cols)
+	m.SubTo(other, dst)
+	return dst
+}
+
+// SubTo stores m - other in dst, which can be m or other to work in
+// place. Panics if shapes differ.
+func (m *M) SubTo(other, dst *M) {
+	zip(m, other, dst, "subtract", func(a, b float64) float64 { return a - b })
+}
+
+// Scale returns alpha * m as a new matrix.
+func (m *M) Scale(alpha float64) *M {
+	dst := New(m.rows, m.cols)
+	m.ScaleTo(alpha, dst)
+	return dst
+}
+
+// ScaleTo stores alpha * m in dst, which can be m to work in place.
+// Panics if shapes differ.
+func (m *M) ScaleTo(alpha float64, dst *M) {
+	zip(m, m, dst, "scale", func(a, _ float64) float64 { return alpha * a })
+}
+
+// AddScaled returns m + alpha * other as a new matrix. Panics if shapes
+// differ.
+func (m *M) AddScaled(alpha float64, other *M) *M {
+	dst := New(m.rows, m.cols)
+	m.AddScaledTo(alpha, other, dst)
+	return dst
+}
+
+// AddScaledTo stores m + alpha * other in dst, which can be m or other to
+// work in place. Panics if shapes differ.
+func (m *M) AddScaledTo(alpha float64, other, dst *M) {
+	zip(m, other, dst, "add", func(a, b float64) float64 { return a + alpha*b })
+}
+
+// MulElem returns the element-wise (Hadamard) product of m and other as a
+// new matrix. Panics if shapes differ.
+func (m *M) MulElem(other *M) *M {
+	dst := New(m.rows, m.cols)
+	m.MulElemTo(other, dst)
+	return dst
+}
+
+// MulElemTo stores the element-wise product of m and other in dst, which
+// can be m or other to work in place. Panics if shapes differ.
+func (m *M) MulElemTo(other, dst *M) {
+	zip(m, other, dst, "multiply", func(a, b float64) float64 { return a * b })
+}
+
+// DivElem returns the element-wise quotient of m and other as a new
+// matrix. Division by zero follows IEEE rules. Panics if shapes differ.
+func (m *M) DivElem(other *M) *M {
+	dst := New(m.rows, m.cols)
+	m.DivElemTo(other, dst)
+	return dst
+}
+
+// DivElemTo stores the element-wise quotient of m and other in dst, which
+// can be m or other to work in place. Panics if shapes differ.
+func (m *M) DivElemTo(other, dst *M) {
+	zip(m, other, dst, "divide", func(a, b float64) float64 { return a / b })
+}
+
+// Kron returns the Kronecker product of m and other: a block matrix with
+// m.Get(i, j) * other as block i, j.
+func (m *M) Kron(other *M) *M {
+	dst := New(m.rows*other.rows, m.cols*other.cols)
+	m.KronTo(other, dst)
+	return dst
+}
+
+// KronTo stores the Kronecker product of m and other in dst, which must
+// not share memory with either. Panics if dst has the wrong shape.
+func (m *M) KronTo(other, dst *M) {
+	if dst.rows != m.rows*other.rows || dst.cols != m.cols*other.cols {
+		panic(errorf(ErrShape, "destination has the wrong shape: %dx%d", dst.rows, dst.cols))
+	}
+	for j := 1; j <= m.cols; j++ {
+		for i := 1; i <= m.rows; i++ {
+			block := dst.View((i-1)*other.rows+1, (j-1)*other.cols+1, i*other.rows, j*other.cols)
+			other.ScaleTo(m.Get(i, j), block)
+		}
+	}
+}
+
+// Outer returns the outer product xy' of vectors x and y, each of them
+// either a row or a column.
+func Outer(x, y *M) *M {
+	nx, _ := vecLen(x)
+	ny, _ := vecLen(y)
+	dst := New(nx, ny)
+	OuterTo(x, y, dst)
+	return dst
+}
+
+// OuterTo stores the outer product xy' in dst, which must not share memory
+// with x or y. Panics if x or y are not vectors or dst has the wrong shape.
+func OuterTo(x, y, dst *M) {
+	nx, sx := vecLen(x)
+	ny, sy := vecLen(y)
+	if dst.rows != nx || dst.cols != ny {
+		panic(errorf(ErrShape, "destination has the wrong shape: %dx%d", dst.rows, dst.cols))
+	}
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			dst.data[dst.at(i+1, j+1)] = x.data[i*sx] * y.data[j*sy]
+		}
+	}
+}
+
+// Dot returns the dot product of vectors x and y, each of them either a
+// row or a column. Panics if they are not vectors of the same length.
+func Dot(x, y *M) float64 {
+	nx, sx := vecLen(x)
+	ny, sy := vecLen(y)
+	if nx != ny {
+		panic(errorf(ErrShape, "can't take dot product of vectors of lengths %d and %d", nx, ny))
+	}
+	var s float64
+	for i := 0; i < nx; i++ {
+		s += x.data[i*sx] * y.data[i*sy]
+	}
+	return s
+}
+
+// vecLen returns the length of vector v and the distance between its
+// elements in v.data. Panics if v is not a row or column vector.
+func vecLen(v *M) (int, int) {
+	switch {
+	case v.cols == 1:
+		return v.rows, v.rowStride
+	case v.rows == 1:
+		return v.cols, v.colStride
+	}
+	panic(errorf(ErrShape, "need a vector, have %dx%d matrix", v.rows, v.cols))
+}
+
+// zip sets dst(i, j) = fn(m(i, j), other(i, j)) for every element. Panics
+// naming op if shapes differ.
+func zip(m, other, dst *M, op string, fn func(a, b float64) float64) {
+	if m.rows != other.rows || m.cols != other.cols {
+		panic(errorf(ErrShape, "can't %s matrices of shapes %dx%d and %dx%d", op, m.rows, m.cols, other.rows, other.cols))
+	}
+	if dst.rows != m.rows || dst.cols != m.cols {
+		panic(errorf(ErrShape, "destination has the wrong shape: %dx%d", dst.rows, dst.cols))
+	}
+	for j := 1; j <= m.cols; j++ {
+		for i := 1; i <= m.rows; i++ {
+			dst.data[dst.at(i, j)] = fn(m.data[m.at(i, j)], other.data[other.at(i, j)])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package mat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElementWise(t *testing.T) {
+	a := New(2, 2, 1, 2, 3, 4)
+	b := New(2, 2, 5, 6, 7, 8)
+	assert.True(t, a.Add(b).Equals(New(2, 2, 6, 8, 10, 12)))
+	assert.True(t, b.Sub(a).Equals(New(2, 2, 4, 4, 4, 4)))
+	assert.True(t, a.Scale(-2).Equals(New(2, 2, -2, -4, -6, -8)))
+	assert.True(t, a.AddScaled(2, b).Equals(New(2, 2, 11, 14, 17, 20)))
+	assert.True(t, a.MulElem(b).Equals(New(2, 2, 5, 12, 21, 32)))
+	assert.True(t, b.DivElem(a).Equals(New(2, 2, 5, 3, 7.0/3, 2)))
+	// operands are left alone
+	assert.True(t, a.Equals(New(2, 2, 1, 2, 3, 4)))
+	assert.True(t, b.Equals(New(2, 2, 5, 6, 7, 8)))
+
+	d := New(1, 2, 1, 0).DivElem(New(1, 2, 0, 0))
+	assert.True(t, math.IsInf(d.Get(1, 1), 1))
+	assert.True(t, math.IsNaN(d.Get(1, 2)))
+
+	assert.Panics(t, func() { a.Add(New(2, 3)) })
+	assert.Panics(t, func() { a.SubTo(b, New(3, 2)) })
+}
+
+func TestElementWiseTo(t *testing.T) {
+	a := New(2, 2, 1, 2, 3, 4)
+	b := New(2, 2, 5, 6, 7, 8)
+
+	// in place on the receiver and on the other operand
+	c := a.Clone()
+	c.AddTo(b, c)
+	assert.True(t, c.Equals(New(2, 2, 6, 8, 10, 12)))
+	c = b.Clone()
+	a.SubTo(c, c)
+	assert.True(t, c.Equals(New(2, 2, -4, -4, -4, -4)))
+	c.ScaleTo(0.5, c)
+	assert.True(t, c.Equals(New(2, 2, -2, -2, -2, -2)))
+
+	// into views
+	m := New(2, 3)
+	a.AddScaledTo(10, b, m.View(1, 2, 2, 3))
+	assert.True(t, m.Equals(New(2, 3, 0, 51, 62, 0, 73, 84)))
+	a.T().MulElemTo(b, m.View(1, 1, 2, 2))
+	assert.True(t, m.Equals(New(2, 3, 5, 18, 62, 14, 32, 84)))
+	a.Row(2).DivElemTo(a.Row(2), m.Col(3).T())
+	assert.True(t, m.Col(3).Equals(Vec(1, 1)))
+}
+
+func TestKron(t *testing.T) {
+	a := New(2, 2, 1, 2, 3, 4)
+	b := New(1, 2, 0, 5)
+	assert.True(t, a.Kron(b).Equals(New(2, 4,
+		0, 5, 0, 10,
+		0, 15, 0, 20)))
+	assert.True(t, b.Kron(a).Equals(New(2, 4,
+		0, 0, 5, 10,
+		0, 0, 15, 20)))
+	assert.True(t, Eye(2).Kron(Eye(3)).Equals(Eye(6)))
+	assert.Panics(t, func() { a.KronTo(b, New(2, 2)) })
+}
+
+func TestOuterDot(t *testing.T) {
+	x := Vec(1, 2, 3)
+	y := New(1, 2, 4, 5)
+	assert.True(t, Outer(x, y).Equals(New(3, 2, 4, 5, 8, 10, 12, 15)))
+	assert.True(t, Outer(x, y).Equals(x.Mul(y)))
+	dst := New(3, 2)
+	OuterTo(x, y.T(), dst)
+	assert.True(t, dst.Equals(New(3, 2, 4, 5, 8, 10, 12, 15)))
+
+	assert.Equal(t, 14.0, Dot(x, x))
+	assert.Equal(t, 32.0, Dot(x, New(1, 3, 4, 5, 6)))
+	m := New(2, 3, 1, 2, 3, 4, 5, 6)
+	assert.Equal(t, 32.0, Dot(m.Row(1), m.Row(2)))
+
+	assert.Panics(t, func() { Dot(x, y) })
+	assert.Panics(t, func() { Dot(m, x) })
+	assert.Panics(t, func() { OuterTo(x, y, New(2, 3)) })
+}
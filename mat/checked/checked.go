@@ -0,0 +1,313 @@
+// Package checked wraps the functions of package mat that panic on invalid
+// input, such as mismatched shapes or out of range indices, returning an
+// error instead. Errors are the *mat.Error values package mat would have
+// panicked with, so they can be matched with errors.Is against mat.ErrShape,
+// mat.ErrIndex and the other kinds. Functions not wrapped here can be called
+// through Try.
+package checked
+
+import "github.com/rciurlea/cn/mat"
+
+// Try calls fn, returning the *mat.Error it panics with, if any. Other
+// panics are not recovered.
+func Try(fn func()) (err error) {
+	defer catch(&err)
+	fn()
+	return nil
+}
+
+// catch recovers a panic with a *mat.Error into err. It must be deferred
+// directly.
+func catch(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	e, ok := r.(*mat.Error)
+	if !ok {
+		panic(r)
+	}
+	*err = e
+}
+
+// New is mat.New returning an error for an invalid size.
+func New(rows, cols int, xs ...float64) (m *mat.M, err error) {
+	defer catch(&err)
+	return mat.New(rows, cols, xs...), nil
+}
+
+// Vec is mat.Vec returning an error for an empty vector.
+func Vec(xs ...float64) (v *mat.M, err error) {
+	defer catch(&err)
+	return mat.Vec(xs...), nil
+}
+
+// Get is m.Get returning an error for invalid indices.
+func Get(m *mat.M, row, col int) (v float64, err error) {
+	defer catch(&err)
+	return m.Get(row, col), nil
+}
+
+// Set is m.Set returning an error for invalid indices.
+func Set(m *mat.M, row, col int, value float64) (err error) {
+	defer catch(&err)
+	m.Set(row, col, value)
+	return nil
+}
+
+// Mul is m.Mul returning an error for incompatible shapes.
+func Mul(m *mat.M, other mat.Matrix) (p *mat.M, err error) {
+	defer catch(&err)
+	return m.Mul(other), nil
+}
+
+// Add is m.Add returning an error for different shapes.
+func Add(m, other *mat.M) (s *mat.M, err error) {
+	defer catch(&err)
+	return m.Add(other), nil
+}
+
+// Sub is m.Sub returning an error for different shapes.
+func Sub(m, other *mat.M) (d *mat.M, err error) {
+	defer catch(&err)
+	return m.Sub(other), nil
+}
+
+// AddScaled is m.AddScaled returning an error for different shapes.
+func AddScaled(m *mat.M, alpha float64, other *mat.M) (s *mat.M, err error) {
+	defer catch(&err)
+	return m.AddScaled(alpha, other), nil
+}
+
+// MulElem is m.MulElem returning an error for different shapes.
+func MulElem(m, other *mat.M) (p *mat.M, err error) {
+	defer catch(&err)
+	return m.MulElem(other), nil
+}
+
+// DivElem is m.DivElem returning an error for different shapes.
+func DivElem(m, other *mat.M) (q *mat.M, err error) {
+	defer catch(&err)
+	return m.DivElem(other), nil
+}
+
+// Outer is mat.Outer returning an error if x or y are not vectors.
+func Outer(x, y *mat.M) (p *mat.M, err error) {
+	defer catch(&err)
+	return mat.Outer(x, y), nil
+}
+
+// Dot is mat.Dot returning an error if x and y are not vectors of the
+// same length.
+func Dot(x, y *mat.M) (d float64, err error) {
+	defer catch(&err)
+	return mat.Dot(x, y), nil
+}
+
+// Augment is m.Augment returning an error for different heights.
+func Augment(m, other *mat.M) (a *mat.M, err error) {
+	defer catch(&err)
+	return m.Augment(other), nil
+}
+
+// Slice is m.Slice returning an error for invalid indices.
+func Slice(m *mat.M, r1, c1, r2, c2 int) (s *mat.M, err error) {
+	defer catch(&err)
+	return m.Slice(r1, c1, r2, c2), nil
+}
+
+// View is m.View returning an error for invalid indices.
+func View(m *mat.M, r1, c1, r2, c2 int) (v *mat.M, err error) {
+	defer catch(&err)
+	return m.View(r1, c1, r2, c2), nil
+}
+
+// SolveUpper is mat.SolveUpper returning an error for a wrong shape.
+func SolveUpper(A *mat.M) (x *mat.M, err error) {
+	defer catch(&err)
+	return mat.SolveUpper(A)
+}
+
+// SolveLower is mat.SolveLower returning an error for wrong shapes.
+func SolveLower(L, B *mat.M) (x *mat.M, err error) {
+	defer catch(&err)
+	return mat.SolveLower(L, B)
+}
+
+// SolveTriangular is mat.SolveTriangular returning an error for wrong
+// shapes.
+func SolveTriangular(T, B *mat.M, flags mat.TriFlag) (x *mat.M, err error) {
+	defer catch(&err)
+	return mat.SolveTriangular(T, B, flags)
+}
+
+// SolveGaussSimple is mat.SolveGaussSimple returning an error for wrong
+// shapes.
+func SolveGaussSimple(A, B *mat.M, opts ...mat.SolveOption) (x *mat.M, err error) {
+	defer catch(&err)
+	return mat.SolveGaussSimple(A, B, opts...)
+}
+
+// SolveGaussPartial is mat.SolveGaussPartial returning an error for wrong
+// shapes.
+func SolveGaussPartial(A, B *mat.M, opts ...mat.SolveOption) (x *mat.M, err error) {
+	defer catch(&err)
+	return mat.SolveGaussPartial(A, B, opts...)
+}
+
+// SolveGaussFull is mat.SolveGaussFull returning an error for wrong
+// shapes.
+func SolveGaussFull(A, B *mat.M, opts ...mat.SolveOption) (x *mat.M, err error) {
+	defer catch(&err)
+	return mat.SolveGaussFull(A, B, opts...)
+}
+
+// SolveGeneral is mat.SolveGeneral returning an error for wrong shapes.
+func SolveGeneral(A, b *mat.M, tol float64) (s *mat.Solution, err error) {
+	defer catch(&err)
+	return mat.SolveGeneral(A, b, tol)
+}
+
+// SolveLeastSquares is mat.SolveLeastSquares returning an error for wrong
+// shapes.
+func SolveLeastSquares(A, b *mat.M) (x *mat.M, res float64, err error) {
+	defer catch(&err)
+	return mat.SolveLeastSquares(A, b)
+}
+
+// SolveCholesky is mat.SolveCholesky returning an error for wrong shapes
+// or a non symmetrical A.
+func SolveCholesky(A, b *mat.M) (x *mat.M, err error) {
+	defer catch(&err)
+	return mat.SolveCholesky(A, b)
+}
+
+// GaussJordan is mat.GaussJordan returning an error for a non square A.
+func GaussJordan(A *mat.M, opts ...mat.SolveOption) (inv *mat.M, err error) {
+	defer catch(&err)
+	return mat.GaussJordan(A, opts...)
+}
+
+// GaussJordanFull is mat.GaussJordanFull returning an error for a non
+// square A.
+func GaussJordanFull(A *mat.M, opts ...mat.SolveOption) (inv *mat.M, err error) {
+	defer catch(&err)
+	return mat.GaussJordanFull(A, opts...)
+}
+
+// LU is mat.LU returning an error for a non square A.
+func LU(A *mat.M) (L, U *mat.M, err error) {
+	defer catch(&err)
+	return mat.LU(A)
+}
+
+// PLU is mat.PLU returning an error for a non square A.
+func PLU(A *mat.M) (P, L, U *mat.M, err error) {
+	defer catch(&err)
+	return mat.PLU(A)
+}
+
+// FactorLU is mat.FactorLU returning an error for a non square A.
+func FactorLU(A *mat.M) (f *mat.LUFactorization, err error) {
+	defer catch(&err)
+	return mat.FactorLU(A)
+}
+
+// Cholesky is mat.Cholesky returning an error for a non square or non
+// symmetrical A.
+func Cholesky(A *mat.M) (B *mat.M, err error) {
+	defer catch(&err)
+	return mat.Cholesky(A)
+}
+
+// FactorCholesky is mat.FactorCholesky returning an error for a non square
+// or non symmetrical A.
+func FactorCholesky(A *mat.M) (f *mat.CholeskyFactorization, err error) {
+	defer catch(&err)
+	return mat.FactorCholesky(A)
+}
+
+// FactorLDL is mat.FactorLDL returning an error for a non square or non
+// symmetrical A.
+func FactorLDL(A *mat.M) (f *mat.LDLFactorization, err error) {
+	defer catch(&err)
+	return mat.FactorLDL(A)
+}
+
+// FactorBunchKaufman is mat.FactorBunchKaufman returning an error for a
+// non square or non symmetrical A.
+func FactorBunchKaufman(A *mat.M) (f *mat.LDLFactorization, err error) {
+	defer catch(&err)
+	return mat.FactorBunchKaufman(A)
+}
+
+// QR is mat.QR returning an error for an A with more columns than rows
+// when using the default Gram-Schmidt method.
+func QR(A *mat.M, method ...mat.QRMethod) (Q, R *mat.M, err error) {
+	defer catch(&err)
+	Q, R = mat.QR(A, method...)
+	return Q, R, nil
+}
+
+// EigenSym is mat.EigenSym returning an error for invalid arguments.
+func EigenSym(A *mat.M, epsilon float64, maxIterations uint) (values, vectors *mat.M, err error) {
+	defer catch(&err)
+	return mat.EigenSym(A, epsilon, maxIterations)
+}
+
+// Eigen is mat.Eigen returning an error for invalid arguments.
+func Eigen(A *mat.M, epsilon float64, maxIterations uint) (values, vectors *mat.M, err error) {
+	defer catch(&err)
+	return mat.Eigen(A, epsilon, maxIterations)
+}
+
+// Det is mat.Det returning an error for a non square A.
+func Det(A *mat.M) (det float64, err error) {
+	defer catch(&err)
+	return mat.Det(A), nil
+}
+
+// Cond is mat.Cond returning an error for a non square A or an unsupported
+// norm.
+func Cond(A *mat.M, t mat.NormType) (c float64, err error) {
+	defer catch(&err)
+	return mat.Cond(A, t)
+}
+
+// SolveJacobi is mat.SolveJacobi returning an error for invalid arguments.
+func SolveJacobi(A mat.Matrix, b, x0 *mat.M, epsilon float64, maxIterations uint, opts ...mat.IterOption) (x *mat.M, res *mat.IterResult, err error) {
+	defer catch(&err)
+	return mat.SolveJacobi(A, b, x0, epsilon, maxIterations, opts...)
+}
+
+// SolveGaussSeidel is mat.SolveGaussSeidel returning an error for invalid
+// arguments.
+func SolveGaussSeidel(A mat.Matrix, b, x0 *mat.M, epsilon float64, maxIterations uint, opts ...mat.IterOption) (x *mat.M, res *mat.IterResult, err error) {
+	defer catch(&err)
+	return mat.SolveGaussSeidel(A, b, x0, epsilon, maxIterations, opts...)
+}
+
+// SolveSOR is mat.SolveSOR returning an error for invalid arguments.
+func SolveSOR(A mat.Matrix, b, x0 *mat.M, omega, epsilon float64, maxIterations uint, opts ...mat.IterOption) (x *mat.M, res *mat.IterResult, err error) {
+	defer catch(&err)
+	return mat.SolveSOR(A, b, x0, omega, epsilon, maxIterations, opts...)
+}
+
+// SolveCG is mat.SolveCG returning an error for invalid arguments.
+func SolveCG(A mat.Matrix, b, x0 *mat.M, epsilon float64, maxIterations uint, opts ...mat.IterOption) (x *mat.M, res *mat.IterResult, err error) {
+	defer catch(&err)
+	return mat.SolveCG(A, b, x0, epsilon, maxIterations, opts...)
+}
+
+// SolveBiCGSTAB is mat.SolveBiCGSTAB returning an error for invalid
+// arguments.
+func SolveBiCGSTAB(A mat.Matrix, b, x0 *mat.M, epsilon float64, maxIterations uint, opts ...mat.IterOption) (x *mat.M, res *mat.IterResult, err error) {
+	defer catch(&err)
+	return mat.SolveBiCGSTAB(A, b, x0, epsilon, maxIterations, opts...)
+}
+
+// SolveGMRES is mat.SolveGMRES returning an error for invalid arguments.
+func SolveGMRES(A mat.Matrix, b, x0 *mat.M, epsilon float64, maxIterations, restart uint, opts ...mat.IterOption) (x *mat.M, res *mat.IterResult, err error) {
+	defer catch(&err)
+	return mat.SolveGMRES(A, b, x0, epsilon, maxIterations, restart, opts...)
+}
@@ -0,0 +1,82 @@
+package checked
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rciurlea/cn/mat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShapeErrors(t *testing.T) {
+	_, err := New(0, 3)
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	_, err = New(-1, 3)
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	_, err = New(2, -2)
+	assert.True(t, errors.Is(err, mat.ErrShape))
+
+	A := mat.New(2, 3)
+	_, err = Mul(A, A)
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	assert.EqualError(t, err, "can't multiply matrices of shapes 2x3 and 2x3")
+	_, err = Add(A, mat.Eye(2))
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	_, err = Dot(A, mat.Vec(1, 2))
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	_, err = Augment(A, mat.Eye(3))
+	assert.True(t, errors.Is(err, mat.ErrShape))
+
+	_, err = SolveGaussPartial(A, mat.Vec(1, 2))
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	_, err = GaussJordan(A)
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	_, _, err = QR(A)
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	_, _, err = QR(A, mat.QRHouseholder)
+	assert.NoError(t, err)
+	_, _, err = SolveCG(A, mat.Vec(1, 2), mat.Vec(0, 0), 1e-6, 10)
+	assert.True(t, errors.Is(err, mat.ErrShape))
+}
+
+func TestIndexErrors(t *testing.T) {
+	A := mat.Eye(2)
+	_, err := Get(A, 3, 1)
+	assert.True(t, errors.Is(err, mat.ErrIndex))
+	assert.True(t, errors.Is(Set(A, 0, 1, 5), mat.ErrIndex))
+	_, err = View(A, 2, 1, 3, 2)
+	assert.True(t, errors.Is(err, mat.ErrIndex))
+	_, err = Slice(A, 1, 1, 2, 3)
+	assert.True(t, errors.Is(err, mat.ErrIndex))
+
+	var merr *mat.Error
+	assert.True(t, errors.As(err, &merr))
+	assert.Equal(t, mat.ErrIndex, merr.Kind)
+}
+
+func TestRuntimeErrorsPassThrough(t *testing.T) {
+	_, err := SolveGaussPartial(mat.New(2, 2, 1, 2, 2, 4), mat.Vec(1, 3))
+	assert.True(t, errors.Is(err, mat.ErrInconsistent))
+	_, err = Cholesky(mat.New(2, 2, 1, 2, 2, 1))
+	assert.True(t, errors.Is(err, mat.ErrNotPositiveDefinite))
+	_, err = Cholesky(mat.New(2, 2, 1, 2, 3, 1))
+	assert.True(t, errors.Is(err, mat.ErrNotSymmetric))
+
+	x, err := SolveGaussPartial(mat.Eye(2), mat.Vec(1, 2))
+	assert.NoError(t, err)
+	assert.True(t, x.Equals(mat.Vec(1, 2)))
+}
+
+func TestTry(t *testing.T) {
+	f, err := FactorLU(mat.Eye(2))
+	assert.NoError(t, err)
+	var x *mat.M
+	err = Try(func() { x = f.Solve(mat.Vec(1, 2, 3)) })
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	assert.Nil(t, x)
+	assert.NoError(t, Try(func() { x = f.Solve(mat.Vec(1, 2)) }))
+	assert.True(t, x.Equals(mat.Vec(1, 2)))
+
+	// panics not coming from package mat are not recovered
+	assert.Panics(t, func() { Try(func() { panic("boom") }) })
+}
@@ -0,0 +1,131 @@
+package mat
+
+import "math"
+
+// CholeskyFactorization is a Cholesky decomposition A = LL' of a symmetric
+// positive definite matrix, kept around to solve many systems with the same
+// matrix and to be cheaply updated when A changes by a rank-one term.
+type CholeskyFactorization struct {
+	l *M
+}
+
+// FactorCholesky computes the Cholesky decomposition of symmetrical matrix
+// A. A is not mutated. Panics if A is not square or symmetrical. Returns
+// error if A is not positive definite.
+func FactorCholesky(A *M) (*CholeskyFactorization, error) {
+	L, err := Cholesky(A)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i <= L.rows; i++ {
+		if L.Get(i, i) == 0 {
+			return nil, errorf(ErrNotPositiveDefinite, "zero diagonal value at %d, %d", i, i)
+		}
+	}
+	return &CholeskyFactorization{l: L}, nil
+}
+
+// SolveCholesky solves Ax = b for symmetric positive definite A using its
+// Cholesky decomposition. Panics if A is not square or symmetrical or b is
+// not a vector the size of A. Returns error if A is not positive definite.
+func SolveCholesky(A, b *M) (*M, error) {
+	f, err := FactorCholesky(A)
+	if err != nil {
+		return nil, err
+	}
+	return f.Solve(b), nil
+}
+
+// L returns the lower triangular factor.
+func (f *CholeskyFactorization) L() *M {
+	return f.l.Clone()
+}
+
+// Solve solves Ax = b. Panics if b is not a vector the size of A.
+func (f *CholeskyFactorization) Solve(b *M) *M {
+	if b.cols != 1 || b.rows != f.l.rows {
+		panic(errorf(ErrShape, "b vector has the wrong shape: %d x %d", b.rows, b.cols))
+	}
+	return cholSolve(f.l, b)
+}
+
+// SolveMany solves AX = B. Panics if B does not have as many rows as A.
+func (f *CholeskyFactorization) SolveMany(B *M) *M {
+	if B.rows != f.l.rows {
+		panic(errorf(ErrShape, "B matrix has the wrong shape: %d x %d", B.rows, B.cols))
+	}
+	return cholSolve(f.l, B)
+}
+
+// Inverse returns the inverse of A.
+func (f *CholeskyFactorization) Inverse() *M {
+	return f.SolveMany(Eye(f.l.rows))
+}
+
+// Det returns the determinant of A. It easily over or underflows for large
+// matrices, use LogDet for those.
+func (f *CholeskyFactorization) Det() float64 {
+	return math.Exp(f.LogDet())
+}
+
+// LogDet returns the natural logarithm of the determinant of A.
+func (f *CholeskyFactorization) LogDet() float64 {
+	var s float64
+	for i := 1; i <= f.l.rows; i++ {
+		s += math.Log(f.l.Get(i, i))
+	}
+	return 2 * s
+}
+
+// Update modifies the factorization in place to that of A + xx', in
+// O(n^2). x is not mutated. Panics if x is not a vector the size of A.
+func (f *CholeskyFactorization) Update(x *M) {
+	if x.cols != 1 || x.rows != f.l.rows {
+		panic(errorf(ErrShape, "x vector has the wrong shape: %d x %d", x.rows, x.cols))
+	}
+	cholRankOne(f.l, x.Clone(), 1)
+}
+
+// Downdate modifies the factorization in place to that of A - xx', in
+// O(n^2). x is not mutated. Panics if x is not a vector the size of A.
+// Returns error, leaving the factorization unchanged, if A - xx' is not
+// positive definite.
+func (f *CholeskyFactorization) Downdate(x *M) error {
+	if x.cols != 1 || x.rows != f.l.rows {
+		panic(errorf(ErrShape, "x vector has the wrong shape: %d x %d", x.rows, x.cols))
+	}
+	L := f.l.Clone()
+	if k := cholRankOne(L, x.Clone(), -1); k != 0 {
+		return errorf(ErrNotPositiveDefinite, "downdated matrix not positive definite at %d, %d", k, k)
+	}
+	f.l = L
+	return nil
+}
+
+// cholRankOne overwrites L with the Cholesky factor of LL' + sign*xx',
+// using x as scratch space. Returns the index where a non-positive
+// diagonal value was found, or 0 on success.
+func cholRankOne(L, x *M, sign float64) int {
+	n := L.rows
+	for k := 1; k <= n; k++ {
+		lkk, xk := L.Get(k, k), x.Get(k, 1)
+		r2 := lkk*lkk + sign*xk*xk
+		if r2 <= 0 {
+			return k
+		}
+		r := math.Sqrt(r2)
+		c, s := r/lkk, xk/lkk
+		L.Set(k, k, r)
+		for i := k + 1; i <= n; i++ {
+			lik := (L.Get(i, k) + sign*s*x.Get(i, 1)) / c
+			L.Set(i, k, lik)
+			x.Set(i, 1, c*x.Get(i, 1)-s*lik)
+		}
+	}
+	return 0
+}
+
+// cholSolve solves LL'X = B by forward and back substitution.
+func cholSolve(L, b *M) *M {
+	return triSolve(L, triSolve(L, b, 0), TriTrans)
+}
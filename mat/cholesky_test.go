@@ -0,0 +1,69 @@
+package mat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveCholesky(t *testing.T) {
+	A := New(3, 3, 4, 12, -16, 12, 37, -43, -16, -43, 98)
+	x, err := SolveCholesky(A, Vec(1, 2, 3))
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(1, 2, 3), A.Mul(x), 1e-9)
+
+	_, err = SolveCholesky(New(2, 2, 1, 2, 2, 1), Vec(1, 1))
+	assert.Error(t, err)
+	_, err = SolveCholesky(New(2, 2, 1, 1, 1, 1), Vec(1, 1))
+	assert.Error(t, err)
+	assert.Panics(t, func() { SolveCholesky(A, Vec(1, 2)) })
+}
+
+func TestCholeskyFactorization(t *testing.T) {
+	A := New(3, 3, 4, 12, -16, 12, 37, -43, -16, -43, 98)
+	f, err := FactorCholesky(A)
+	assert.NoError(t, err)
+	assertInDelta(t, New(3, 3, 2, 0, 0, 6, 1, 0, -8, 5, 3), f.L(), 1e-12)
+	assertInDelta(t, Eye(3), A.Mul(f.Inverse()), 1e-9)
+	assert.InDelta(t, 36, f.Det(), 1e-9)
+	assert.InDelta(t, math.Log(36), f.LogDet(), 1e-12)
+	B := New(3, 2, 1, 0, 0, 1, 1, 1)
+	assertInDelta(t, B, A.Mul(f.SolveMany(B)), 1e-9)
+
+	// large SPD matrix whose determinant does not fit a float64
+	n := 400
+	D := New(n, n)
+	for i := 1; i <= n; i++ {
+		D.Set(i, i, 10)
+	}
+	f, err = FactorCholesky(D)
+	assert.NoError(t, err)
+	assert.True(t, math.IsInf(f.Det(), 1))
+	assert.InDelta(t, float64(n)*math.Log(10), f.LogDet(), 1e-9)
+}
+
+func TestCholeskyUpdate(t *testing.T) {
+	A := New(3, 3, 4, 12, -16, 12, 37, -43, -16, -43, 98)
+	x := Vec(1, -2, 3)
+	xxT := x.Mul(x.Transpose())
+	Ax := A.Clone()
+	for i := range Ax.data {
+		Ax.data[i] += xxT.data[i]
+	}
+
+	f, _ := FactorCholesky(A)
+	f.Update(x)
+	assertInDelta(t, Vec(1, -2, 3), x, 0)
+	L := f.L()
+	assertInDelta(t, Ax, L.Mul(L.Transpose()), 1e-9)
+
+	assert.NoError(t, f.Downdate(x))
+	L = f.L()
+	assertInDelta(t, A, L.Mul(L.Transpose()), 1e-9)
+
+	// removing more than there is leaves an indefinite matrix
+	f, _ = FactorCholesky(Eye(2))
+	assert.Error(t, f.Downdate(Vec(1, 1)))
+	assertInDelta(t, Eye(2), f.L(), 0)
+}
@@ -0,0 +1,77 @@
+package mat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hilbert(n int) *M {
+	h := New(n, n)
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= n; j++ {
+			h.Set(i, j, 1/float64(i+j-1))
+		}
+	}
+	return h
+}
+
+func TestCond(t *testing.T) {
+	A := New(2, 2, 1, 2, 3, 4)
+	c, err := Cond(A, Norm1)
+	assert.NoError(t, err)
+	assert.InDelta(t, 21, c, 1e-9)
+	c, err = Cond(A, NormInf)
+	assert.NoError(t, err)
+	assert.InDelta(t, 21, c, 1e-9)
+	c, err = Cond(A, Norm2)
+	assert.NoError(t, err)
+	c2, err := Cond2(A)
+	assert.NoError(t, err)
+	assert.Equal(t, c2, c)
+	assert.Panics(t, func() { Cond(A, NormFrobenius) })
+}
+
+func TestCondEstimateHilbert(t *testing.T) {
+	// exact 1-norm condition number of the 5 x 5 Hilbert matrix
+	exact := 943656.0
+	c, err := Cond(hilbert(5), Norm1)
+	assert.NoError(t, err)
+	assert.True(t, c <= exact*1.0001 && c >= exact/3, "estimate %g", c)
+}
+
+func TestCondSingular(t *testing.T) {
+	c, err := Cond(New(2, 2, 1, 2, 2, 4), Norm1)
+	assert.NoError(t, err)
+	assert.True(t, math.IsInf(c, 1))
+}
+
+func TestWithCondLimit(t *testing.T) {
+	H := hilbert(6)
+	b := H.Mul(ones(6))
+	solvers := map[string]func(A, b *M, opts ...SolveOption) (*M, error){
+		"simple":  SolveGaussSimple,
+		"partial": SolveGaussPartial,
+		"full":    SolveGaussFull,
+	}
+	for name, solve := range solvers {
+		t.Run(name, func(t *testing.T) {
+			x, err := solve(H, b)
+			assert.NoError(t, err)
+			assert.NotNil(t, x)
+			x, err = solve(H, b, WithCondLimit(1e6))
+			assert.NotNil(t, x)
+			ce, ok := err.(*CondError)
+			if assert.True(t, ok) {
+				assert.True(t, ce.Cond > 1e6)
+				assert.Equal(t, 1e6, ce.Limit)
+			}
+			_, err = solve(H, b, WithCondLimit(1e12))
+			assert.NoError(t, err)
+		})
+	}
+	inv, err := GaussJordan(H, WithCondLimit(1e6))
+	assert.NotNil(t, inv)
+	assert.IsType(t, &CondError{}, err)
+}
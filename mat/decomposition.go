@@ -8,9 +8,11 @@ import (
 // LU computes the LU decomposition of square matrix A, returning
 // new matrices L and U. A is not mutated. Panics if A is not square.
 // Returns error if operation cannot be completed (e.g. singular matrix).
+// No rows are swapped, so it also fails on invertible matrices that
+// have zero pivots; use PLU or FactorLU for those.
 func LU(A *M) (*M, *M, error) {
 	if A.cols != A.rows {
-		panic("matrix must be square for LU decomposition")
+		panic(errorf(ErrShape, "matrix must be square for LU decomposition"))
 	}
 	// handle trivial case (size 1)
 	if A.cols < 2 {
@@ -24,7 +26,7 @@ func LU(A *M) (*M, *M, error) {
 			if L.Mul(U).Equals(A) {
 				return L, U, nil
 			}
-			return nil, nil, fmt.Errorf("zero pivot found at %d, %d", k, k)
+			return nil, nil, errorf(ErrSingular, "zero pivot found at %d, %d", k, k)
 		}
 		// transform all elements below pivot to zeroes, saving scaling
 		// factors to L matrix
@@ -40,14 +42,114 @@ func LU(A *M) (*M, *M, error) {
 	return L, U, nil
 }
 
+// PLU computes the LU decomposition of square matrix A with partial
+// pivoting, PA = LU, returning permutation matrix P, unit lower triangular
+// L and upper triangular U. A is not mutated. Panics if A is not square.
+// Returns error if A is singular.
+func PLU(A *M) (*M, *M, *M, error) {
+	f, err := FactorLU(A)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return f.P(), f.L(), f.U(), nil
+}
+
+// LUFactorization is an LU decomposition with partial pivoting kept around
+// to solve many systems with the same matrix, each in O(n^2).
+type LUFactorization struct {
+	lu   *M
+	perm []int
+	sign float64
+}
+
+// FactorLU computes the LU decomposition of square matrix A with partial
+// pivoting. A is not mutated. Panics if A is not square. Returns error if
+// A is singular, i.e. a pivot is no larger than a tolerance based on the
+// size and largest element of A.
+func FactorLU(A *M) (*LUFactorization, error) {
+	if A.cols != A.rows {
+		panic(errorf(ErrShape, "matrix must be square for LU decomposition"))
+	}
+	lu, perm, sign := plu(A)
+	tol := rankTolerance(A)
+	for k := 1; k <= lu.rows; k++ {
+		if math.Abs(lu.Get(k, k)) <= tol {
+			return nil, errorf(ErrSingular, "zero pivot found at %d, %d", k, k)
+		}
+	}
+	return &LUFactorization{lu: lu, perm: perm, sign: sign}, nil
+}
+
+// L returns the unit lower triangular factor.
+func (f *LUFactorization) L() *M {
+	L := Eye(f.lu.rows)
+	for i := 2; i <= f.lu.rows; i++ {
+		for j := 1; j < i; j++ {
+			L.Set(i, j, f.lu.Get(i, j))
+		}
+	}
+	return L
+}
+
+// U returns the upper triangular factor.
+func (f *LUFactorization) U() *M {
+	U := New(f.lu.rows, f.lu.cols)
+	for i := 1; i <= f.lu.rows; i++ {
+		for j := i; j <= f.lu.cols; j++ {
+			U.Set(i, j, f.lu.Get(i, j))
+		}
+	}
+	return U
+}
+
+// P returns the permutation matrix, such that PA = LU.
+func (f *LUFactorization) P() *M {
+	P := New(f.lu.rows, f.lu.rows)
+	for i, r := range f.perm {
+		P.Set(i+1, r, 1)
+	}
+	return P
+}
+
+// Solve solves Ax = b. Panics if b is not a vector the size of A.
+func (f *LUFactorization) Solve(b *M) *M {
+	if b.cols != 1 || b.rows != f.lu.rows {
+		panic(errorf(ErrShape, "b vector has the wrong shape: %d x %d", b.rows, b.cols))
+	}
+	return pluSolve(f.lu, f.perm, b)
+}
+
+// SolveMany solves AX = B. Panics if B does not have as many rows as A.
+func (f *LUFactorization) SolveMany(B *M) *M {
+	if B.rows != f.lu.rows {
+		panic(errorf(ErrShape, "B matrix has the wrong shape: %d x %d", B.rows, B.cols))
+	}
+	return pluSolve(f.lu, f.perm, B)
+}
+
+// Det returns the determinant of A.
+func (f *LUFactorization) Det() float64 {
+	det := f.sign
+	for i := 1; i <= f.lu.rows; i++ {
+		det *= f.lu.Get(i, i)
+	}
+	return det
+}
+
+// Inverse returns the inverse of A.
+func (f *LUFactorization) Inverse() *M {
+	return f.SolveMany(Eye(f.lu.rows))
+}
+
 // Cholesky decomposes a symmetrical matrix A into it's B*B' representation.
-// Panics if A is not square or symmetrical.
+// Panics if A is not square or symmetrical. A must be positive definite;
+// see FactorBunchKaufman for indefinite matrices.
 func Cholesky(A *M) (*M, error) {
 	if A.rows != A.cols {
-		panic("need square matrix for Cholesky decomposition")
+		panic(errorf(ErrShape, "need square matrix for Cholesky decomposition"))
 	}
 	if !A.Equals(A.Transpose()) {
-		panic("need symmetrical matrix for Cholesky decomposition")
+		panic(errorf(ErrNotSymmetric, "need symmetrical matrix for Cholesky decomposition"))
 	}
 	B := New(A.rows, A.cols)
 	var s float64
@@ -59,7 +161,7 @@ func Cholesky(A *M) (*M, error) {
 		}
 		s = A.Get(i, i) - s
 		if s < 0 {
-			return nil, fmt.Errorf("negative diagonal value at %d, %d", i, i)
+			return nil, errorf(ErrNotPositiveDefinite, "negative diagonal value at %d, %d", i, i)
 		}
 		B.Set(i, i, math.Sqrt(s))
 		// other elements
@@ -75,56 +177,350 @@ func Cholesky(A *M) (*M, error) {
 	return B, nil
 }
 
-// QR computes the QR decomposition of square matrix A, returning
-// orthogonal matrix Q and upper triangular matrix R. A is not mutated.
-// Panics if A is not square.
-func QR(A *M) (*M, *M) {
-	Q := GramSchmidt(A)
+// QRMethod selects the algorithm used by QR.
+type QRMethod int
+
+// Algorithms available for QR decomposition.
+const (
+	// QRGramSchmidt orthogonalizes the columns of A with GramSchmidt. It
+	// is the default, but loses orthogonality on ill-conditioned input.
+	QRGramSchmidt QRMethod = iota
+	// QRHouseholder uses Householder reflections, see HouseholderQR.
+	QRHouseholder
+	// QRGivens uses Givens rotations, see GivensQR.
+	QRGivens
+)
+
+// QR computes the QR decomposition of matrix A, returning orthogonal
+// matrix Q and upper triangular matrix R. A is not mutated. An optional
+// method picks the algorithm, Gram-Schmidt by default. The factors are
+// thin: for an m x n matrix with k = min(m, n), Q is m x k and R is k x n.
+// Gram-Schmidt panics if A has more columns than rows; it uses the
+// modified variant with reorthogonalization, and leaves zero columns in
+// Q for linearly dependent columns of A.
+func QR(A *M, method ...QRMethod) (*M, *M) {
+	m := QRGramSchmidt
+	if len(method) > 0 {
+		m = method[0]
+	}
+	switch m {
+	case QRHouseholder:
+		return HouseholderQR(A, true)
+	case QRGivens:
+		return GivensQR(A, true)
+	}
+	if A.cols > A.rows {
+		panic(errorf(ErrShape, "Gram-Schmidt QR needs at least as many rows as columns"))
+	}
+	Q, _ := orthonormalize(A, ModifiedGS, true)
 	R := Q.Transpose().Mul(A)
 	return Q, R
 }
 
-// GramSchmidt generates a orthonormal base for the column space of A.
-// A needs to have linearly independent columns.
-func GramSchmidt(A *M) *M {
-	// panic if A is "wide", it certainly can't have independent columns
-	if A.cols > A.rows {
-		panic("A needs to have independent columns")
+// HouseholderQR computes the QR decomposition of an m x n matrix A using
+// Householder reflections. It stays accurate for singular and nearly
+// singular matrices. With k = min(m, n), a thin decomposition returns an
+// m x k matrix Q and a k x n matrix R, a full one an m x m Q and an m x n
+// R. A is not mutated.
+func HouseholderQR(A *M, thin bool) (*M, *M) {
+	Q, R, _ := householder(A, false)
+	return qrShape(Q, R, thin)
+}
+
+// HouseholderQRPivot computes the QR decomposition of A with column
+// pivoting, AP = QR, where P is a permutation matrix. At each step the
+// remaining column with the largest norm is moved forward, so the
+// diagonal of R decreases in absolute value and trailing small values
+// reveal the numerical rank of A. Shapes are as for HouseholderQR. A is
+// not mutated.
+func HouseholderQRPivot(A *M, thin bool) (*M, *M, *M) {
+	Q, R, perm := householder(A, true)
+	P := New(A.cols, A.cols)
+	for j, c := range perm {
+		P.Set(c, j+1, 1)
 	}
-	B := A.Clone()
-	var dp, s float64
-	for i := 1; i <= B.cols; i++ {
-		// column i in B already contains column i in A, due to cloning
-		// move on to projection subtraction
-		for j := 1; j < i; j++ {
-			// calculate dot product between col i in A and col j in B
-			// also calculate squared norm of col j in B
-			dp, s = 0, 0
-			for k := 1; k <= A.rows; k++ {
-				dp += A.Get(k, i) * B.Get(k, j)
-				s += B.Get(k, j) * B.Get(k, j)
+	Q, R = qrShape(Q, R, thin)
+	return Q, R, P
+}
+
+// GivensQR computes the QR decomposition of an m x n matrix A using Givens
+// rotations, zeroing one element at a time. This suits sparse or nearly
+// triangular matrices, where most rotations can be skipped. Shapes are as
+// for HouseholderQR. A is not mutated.
+func GivensQR(A *M, thin bool) (*M, *M) {
+	R := A.Clone()
+	Q := Eye(A.rows)
+	for j := 1; j <= R.cols && j < R.rows; j++ {
+		for i := R.rows; i > j; i-- {
+			if R.Get(i, j) == 0 {
+				continue
 			}
-			// adjust dot product by squared norm
-			dp /= s
-			// and subtract col j in B scaled by calculated factor
-			for k := 1; k <= A.rows; k++ {
-				B.Set(k, i, B.Get(k, i)-B.Get(k, j)*dp)
+			c, s := givens(R.Get(i-1, j), R.Get(i, j))
+			rotateRows(R, i-1, i, c, s)
+			rotateCols(Q, i-1, i, c, -s)
+			R.Set(i, j, 0)
+		}
+	}
+	return qrShape(Q, R, thin)
+}
+
+// QRUpdate computes the QR decomposition of A + uv' given a full QR
+// decomposition of the m x n matrix A, i.e. an m x m matrix Q and an m x n
+// matrix R, in O(m^2) rather than O(m^2 n) operations. u must be a vector
+// with m rows and v one with n rows. Q and R are not mutated. Panics if
+// shapes don't match.
+func QRUpdate(Q, R, u, v *M) (*M, *M) {
+	if Q.rows != Q.cols || Q.cols != R.rows {
+		panic(errorf(ErrShape, "QRUpdate needs a full QR decomposition"))
+	}
+	if u.cols != 1 || u.rows != R.rows || v.cols != 1 || v.rows != R.cols {
+		panic(errorf(ErrShape, "update vectors have the wrong shape: %d x %d, %d x %d", u.rows, u.cols, v.rows, v.cols))
+	}
+	Q = Q.Clone()
+	R = R.Clone()
+	// A + uv' = Q(R + wv') with w = Q'u
+	w := Q.Transpose().Mul(u)
+	// rotate w into a multiple of e1, which turns R upper Hessenberg
+	for i := w.rows; i > 1; i-- {
+		if w.Get(i, 1) == 0 {
+			continue
+		}
+		c, s := givens(w.Get(i-1, 1), w.Get(i, 1))
+		rotateRows(w, i-1, i, c, s)
+		rotateRows(R, i-1, i, c, s)
+		rotateCols(Q, i-1, i, c, -s)
+	}
+	R.Row(1).AddScaledTo(w.Get(1, 1), v.T(), R.Row(1))
+	// and bring R back to triangular form
+	for i := 1; i <= R.cols && i < R.rows; i++ {
+		if R.Get(i+1, i) == 0 {
+			continue
+		}
+		c, s := givens(R.Get(i, i), R.Get(i+1, i))
+		rotateRows(R, i, i+1, c, s)
+		rotateCols(Q, i, i+1, c, -s)
+		R.Set(i+1, i, 0)
+	}
+	return Q, R
+}
+
+// householder computes a full QR decomposition of A using Householder
+// reflections, optionally pivoting columns. Returns Q, R and the original
+// column index of each column of R.
+func householder(A *M, pivot bool) (*M, *M, []int) {
+	R := A.Clone()
+	Q := Eye(A.rows)
+	perm := make([]int, A.cols)
+	for j := range perm {
+		perm[j] = j + 1
+	}
+	v := make([]float64, A.rows+1)
+	for k := 1; k <= R.cols && k <= R.rows; k++ {
+		if pivot {
+			best, bestNorm := k, -1.0
+			for j := k; j <= R.cols; j++ {
+				var s float64
+				for i := k; i <= R.rows; i++ {
+					s += R.Get(i, j) * R.Get(i, j)
+				}
+				if s > bestNorm {
+					best, bestNorm = j, s
+				}
+			}
+			if best != k {
+				R.SwapCols(best, k)
+				perm[best-1], perm[k-1] = perm[k-1], perm[best-1]
 			}
 		}
+		if k == R.rows {
+			// nothing below the diagonal, only the pivot mattered
+			continue
+		}
+		// build reflector v that maps column k below the diagonal onto e1
+		var norm float64
+		for i := k; i <= R.rows; i++ {
+			norm += R.Get(i, k) * R.Get(i, k)
+		}
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			continue
+		}
+		alpha := -norm
+		if R.Get(k, k) < 0 {
+			alpha = norm
+		}
+		var vnorm float64
+		for i := k; i <= R.rows; i++ {
+			v[i] = R.Get(i, k)
+			if i == k {
+				v[i] -= alpha
+			}
+			vnorm += v[i] * v[i]
+		}
+		if vnorm == 0 {
+			continue
+		}
+		// apply H = I - 2vv'/v'v to R from the left
+		for j := k; j <= R.cols; j++ {
+			var s float64
+			for i := k; i <= R.rows; i++ {
+				s += v[i] * R.Get(i, j)
+			}
+			s = 2 * s / vnorm
+			for i := k; i <= R.rows; i++ {
+				R.Set(i, j, R.Get(i, j)-s*v[i])
+			}
+		}
+		for i := k + 1; i <= R.rows; i++ {
+			R.Set(i, k, 0)
+		}
+		// and accumulate it into Q from the right
+		for i := 1; i <= Q.rows; i++ {
+			var s float64
+			for j := k; j <= Q.cols; j++ {
+				s += Q.Get(i, j) * v[j]
+			}
+			s = 2 * s / vnorm
+			for j := k; j <= Q.cols; j++ {
+				Q.Set(i, j, Q.Get(i, j)-s*v[j])
+			}
+		}
+	}
+	return Q, R, perm
+}
+
+// qrShape cuts full QR factors down to thin ones if requested.
+func qrShape(Q, R *M, thin bool) (*M, *M) {
+	k := R.rows
+	if R.cols < k {
+		k = R.cols
+	}
+	if !thin || k == R.rows {
+		return Q, R
+	}
+	return Q.Slice(1, 1, Q.rows, k), R.Slice(1, 1, k, R.cols)
+}
+
+// givens returns c and s such that rotating (a, b) by [c s; -s c] gives
+// (r, 0).
+func givens(a, b float64) (float64, float64) {
+	if b == 0 {
+		return 1, 0
+	}
+	r := math.Hypot(a, b)
+	return a / r, b / r
+}
+
+// rotateRows applies a plane rotation to rows p and q of A, in place.
+func rotateRows(A *M, p, q int, c, s float64) {
+	for j := 1; j <= A.cols; j++ {
+		ap, aq := A.Get(p, j), A.Get(q, j)
+		A.Set(p, j, c*ap+s*aq)
+		A.Set(q, j, -s*ap+c*aq)
+	}
+}
+
+// GramSchmidt generates a orthonormal base for the column space of A
+// using the modified Gram-Schmidt method. A is not mutated. Panics if A
+// has more columns than rows, returns a *DependentColumnsError if its
+// columns are linearly dependent. See Orthonormalize for the other
+// variants and reorthogonalization.
+func GramSchmidt(A *M) (*M, error) {
+	if A.cols > A.rows {
+		panic(errorf(ErrShape, "A has more columns than rows, they can't be independent"))
+	}
+	return Orthonormalize(A, ModifiedGS, false)
+}
+
+// GSMethod selects the Gram-Schmidt variant used by Orthonormalize.
+type GSMethod int
+
+// Gram-Schmidt variants.
+const (
+	// ClassicalGS projects each column of A against the basis built so
+	// far, all projections computed from the original column.
+	ClassicalGS GSMethod = iota
+	// ModifiedGS subtracts projections one at a time, computing each from
+	// the progressively updated vector, which is much more stable.
+	ModifiedGS
+)
+
+// gsTol is the fraction of its original length a column must keep after
+// orthogonalization to count as linearly independent.
+const gsTol = 1e-10
+
+// DependentColumnsError is returned by Orthonormalize when columns of A
+// are linearly dependent on the columns before them.
+type DependentColumnsError struct {
+	Cols []int
+}
+
+func (e *DependentColumnsError) Error() string {
+	return fmt.Sprintf("linearly dependent columns: %v", e.Cols)
+}
+
+// Unwrap returns ErrSingular.
+func (e *DependentColumnsError) Unwrap() error {
+	return ErrSingular
+}
+
+// Orthonormalize generates an orthonormal base for the column space of A
+// using the selected Gram-Schmidt variant. With reorthogonalize set each
+// vector is orthogonalized a second time, which brings orthogonality
+// down to machine precision for either variant at twice the cost. A is
+// not mutated. Returns a *DependentColumnsError listing every column that
+// is (numerically) a linear combination of the previous ones.
+func Orthonormalize(A *M, method GSMethod, reorthogonalize bool) (*M, error) {
+	Q, dependent := orthonormalize(A, method, reorthogonalize)
+	if len(dependent) > 0 {
+		return nil, &DependentColumnsError{Cols: dependent}
+	}
+	return Q, nil
+}
+
+// orthonormalize is Orthonormalize without the dependency error: columns
+// of Q matching dependent columns of A are left zero, so A = QQ'A still
+// holds. Returns the dependent columns.
+func orthonormalize(A *M, method GSMethod, reorthogonalize bool) (*M, []int) {
+	Q := New(A.rows, A.cols)
+	v := New(A.rows, 1)
+	dps := make([]float64, A.cols+1)
+	var dependent []int
+	passes := 1
+	if reorthogonalize {
+		passes = 2
 	}
-	// normalize vectors in B
-	for i := 1; i <= B.cols; i++ {
-		norm := colLength(B, i)
-		for j := 1; j <= B.rows; j++ {
-			B.Set(j, i, B.Get(j, i)/norm)
+	for i := 1; i <= A.cols; i++ {
+		A.Col(i).CopyTo(v)
+		orig := colLength(v, 1)
+		for p := 0; p < passes; p++ {
+			if method == ModifiedGS {
+				for j := 1; j < i; j++ {
+					axpy(-dot(Q.Col(j), v), Q.Col(j), v)
+				}
+				continue
+			}
+			for j := 1; j < i; j++ {
+				dps[j] = dot(Q.Col(j), v)
+			}
+			for j := 1; j < i; j++ {
+				axpy(-dps[j], Q.Col(j), v)
+			}
+		}
+		norm := colLength(v, 1)
+		if orig == 0 || norm <= gsTol*orig {
+			// leave a zero column, which drops out of later projections
+			dependent = append(dependent, i)
+			continue
 		}
+		axpy(1/norm, v, Q.Col(i))
 	}
-	return B
+	return Q, dependent
 }
 
 func colLength(A *M, col int) float64 {
 	if col < 1 || col > A.cols {
-		panic("invalid column number")
+		panic(errorf(ErrIndex, "invalid column number"))
 	}
 	var s float64
 	for i := 1; i <= A.rows; i++ {
@@ -132,3 +528,67 @@ func colLength(A *M, col int) float64 {
 	}
 	return math.Sqrt(s)
 }
+
+// plu computes the LU decomposition of square matrix A with partial
+// pivoting, packed in a single matrix: U on and above the diagonal and the
+// scaling factors of unit lower triangular L below it. Row i of the
+// result corresponds to row perm[i-1] of A, and sign is the determinant
+// of the permutation. Columns with no usable pivot are skipped, leaving a
+// zero on U's diagonal. A is not mutated.
+func plu(A *M) (*M, []int, float64) {
+	lu := A.Clone()
+	perm := make([]int, A.rows)
+	for i := range perm {
+		perm[i] = i + 1
+	}
+	sign := 1.0
+	for k := 1; k <= lu.cols; k++ {
+		rmax, _ := lu.MaxIndex(k, k, lu.rows, k)
+		pivot := lu.Get(rmax, k)
+		if pivot == 0 {
+			continue
+		}
+		if rmax != k {
+			lu.SwapRows(rmax, k)
+			perm[rmax-1], perm[k-1] = perm[k-1], perm[rmax-1]
+			sign = -sign
+		}
+		for i := k + 1; i <= lu.rows; i++ {
+			f := lu.Get(i, k) / pivot
+			lu.Set(i, k, f)
+			for j := k + 1; j <= lu.cols; j++ {
+				lu.Set(i, j, lu.Get(i, j)-lu.Get(k, j)*f)
+			}
+		}
+	}
+	return lu, perm, sign
+}
+
+// pluSolve solves AX = B for B with any number of columns using a packed
+// decomposition returned by plu. The decomposition must not have zero
+// pivots.
+func pluSolve(lu *M, perm []int, b *M) *M {
+	pb := New(lu.rows, b.cols)
+	for i := 1; i <= lu.rows; i++ {
+		for j := 1; j <= b.cols; j++ {
+			pb.Set(i, j, b.Get(perm[i-1], j))
+		}
+	}
+	// Ly = Pb, L has unit diagonal, then Ux = y
+	return triSolve(lu, triSolve(lu, pb, TriUnit), TriUpper)
+}
+
+// pluSolveTransposed solves A'x = b for vector b using a packed
+// decomposition returned by plu, i.e. U'L'Px = b. The decomposition must
+// not have zero pivots.
+func pluSolveTransposed(lu *M, perm []int, b *M) *M {
+	n := lu.rows
+	// U'w = b, then L'v = w
+	v := triSolve(lu, triSolve(lu, b, TriUpper|TriTrans), TriUnit|TriTrans)
+	// undo the row permutation
+	x := New(n, 1)
+	for i := 1; i <= n; i++ {
+		x.Set(perm[i-1], 1, v.Get(i, 1))
+	}
+	return x
+}
@@ -1,7 +1,9 @@
 package mat
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -65,10 +67,8 @@ func TestColLenght(t *testing.T) {
 
 func TestGramSchmidt(t *testing.T) {
 	A := New(3, 3, 1, 1, 1, -1, 0, 1, 1, 1, 2)
-	var B *M
-	assert.NotPanics(t, func() {
-		B = GramSchmidt(A)
-	})
+	B, err := GramSchmidt(A)
+	assert.NoError(t, err)
 	for i := 1; i <= B.cols; i++ {
 		assert.True(t, almostEqual(1, colLength(B, i)))
 	}
@@ -96,6 +96,24 @@ func TestGramSchmidt(t *testing.T) {
 	}
 }
 
+func TestGramSchmidtDependent(t *testing.T) {
+	A := New(3, 2, 1, 2, 1, 2, 1, 2)
+	_, err := GramSchmidt(A)
+	assert.True(t, errors.Is(err, ErrSingular))
+	if assert.IsType(t, &DependentColumnsError{}, err) {
+		assert.Equal(t, []int{2}, err.(*DependentColumnsError).Cols)
+	}
+	func() {
+		defer func() { assert.True(t, errors.Is(recover().(error), ErrShape)) }()
+		GramSchmidt(New(2, 3))
+	}()
+
+	// QR still factors it, with a zero column in Q
+	Q, R := QR(A)
+	assertInDelta(t, A, Q.Mul(R), 1e-12)
+	assertInDelta(t, New(3, 1), Q.Col(2), 0)
+}
+
 func TestQRDecomposition(t *testing.T) {
 	A := New(3, 3, 12, -51, 4, 6, 167, -68, -4, 24, -41)
 	var Q, R *M
@@ -104,3 +122,176 @@ func TestQRDecomposition(t *testing.T) {
 	})
 	assert.True(t, A.Equals(Q.Mul(R)))
 }
+
+func TestPLU(t *testing.T) {
+	tcs := []*M{
+		New(2, 2, 0, 1, 1, 0),
+		New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 10),
+		New(3, 3, 0, 0, 2, 0, 3, 0, 4, 0, 0),
+		Rand(5, 5),
+	}
+	for i, A := range tcs {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			P, L, U, err := PLU(A)
+			assert.NoError(t, err)
+			assertInDelta(t, P.Mul(A), L.Mul(U), 1e-12)
+			for r := 1; r <= L.rows; r++ {
+				assert.Equal(t, 1.0, L.Get(r, r))
+				for c := r + 1; c <= L.cols; c++ {
+					assert.Equal(t, 0.0, L.Get(r, c))
+					assert.Equal(t, 0.0, U.Get(c, r))
+				}
+			}
+		})
+	}
+	_, _, _, err := PLU(New(3, 3, 1, 2, 3, 4, 5, 6, 2, 4, 6))
+	assert.Error(t, err)
+}
+
+func TestLUFactorization(t *testing.T) {
+	A := New(3, 3, 2, 1, -1, -3, -1, 2, -2, 1, 2)
+	f, err := FactorLU(A)
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(2, 3, -1), f.Solve(Vec(8, -11, -3)), 1e-12)
+	B := New(3, 2, 8, 1, -11, 0, -3, 0)
+	X := f.SolveMany(B)
+	assertInDelta(t, B, A.Mul(X), 1e-12)
+	assert.InDelta(t, -1, f.Det(), 1e-12)
+	assertInDelta(t, Eye(3), A.Mul(f.Inverse()), 1e-12)
+	assert.Panics(t, func() { f.Solve(Vec(1, 2)) })
+
+	// singular only up to rounding
+	_, err = FactorLU(New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9))
+	assert.True(t, errors.Is(err, ErrSingular))
+}
+
+func TestQRMethods(t *testing.T) {
+	tcs := []*M{
+		New(3, 3, 12, -51, 4, 6, 167, -68, -4, 24, -41),
+		New(4, 2, 1, 2, 3, 4, 5, 6, 7, 8),
+		New(2, 4, 1, 2, 3, 4, 5, 6, 7, 8),
+		New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9),
+	}
+	for i, A := range tcs {
+		for _, method := range []QRMethod{QRHouseholder, QRGivens} {
+			t.Run(fmt.Sprintf("#%d method %d", i, method), func(t *testing.T) {
+				Q, R := QR(A, method)
+				k := A.rows
+				if A.cols < k {
+					k = A.cols
+				}
+				assert.Equal(t, k, Q.cols)
+				assert.Equal(t, k, R.rows)
+				assertInDelta(t, A, Q.Mul(R), 1e-12)
+				assertInDelta(t, Eye(k), Q.Transpose().Mul(Q), 1e-12)
+				for r := 2; r <= R.rows; r++ {
+					for c := 1; c < r && c <= R.cols; c++ {
+						assert.Equal(t, 0.0, R.Get(r, c))
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestFullQR(t *testing.T) {
+	A := New(4, 2, 1, 2, 3, 4, 5, 6, 7, 8)
+	for _, qr := range []func(*M, bool) (*M, *M){HouseholderQR, GivensQR} {
+		Q, R := qr(A, false)
+		assert.Equal(t, 4, Q.cols)
+		assert.Equal(t, 4, R.rows)
+		assertInDelta(t, A, Q.Mul(R), 1e-12)
+		assertInDelta(t, Eye(4), Q.Transpose().Mul(Q), 1e-12)
+	}
+}
+
+func TestHouseholderQRPivot(t *testing.T) {
+	A := New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	Q, R, P := HouseholderQRPivot(A, true)
+	assertInDelta(t, A.Mul(P), Q.Mul(R), 1e-12)
+	for i := 2; i <= R.rows; i++ {
+		assert.True(t, math.Abs(R.Get(i-1, i-1)) >= math.Abs(R.Get(i, i)))
+	}
+	// rank 2 shows up as a negligible last diagonal element
+	assert.InDelta(t, 0, R.Get(3, 3), 1e-12)
+
+	// wide matrices pivot on the last row too
+	A = New(2, 3, 1, 0, 0, 0, 0, 5)
+	Q, R, P = HouseholderQRPivot(A, true)
+	assertInDelta(t, A.Mul(P), Q.Mul(R), 1e-12)
+	assert.InDelta(t, 5, math.Abs(R.Get(1, 1)), 1e-12)
+	assert.InDelta(t, 1, math.Abs(R.Get(2, 2)), 1e-12)
+	A = New(2, 4, 1, 2, 3, 4, 2, 4, 6, 8)
+	_, R, _ = HouseholderQRPivot(A, true)
+	assert.True(t, math.Abs(R.Get(1, 1)) > 1)
+	assert.InDelta(t, 0, R.Get(2, 2), 1e-12)
+}
+
+func TestHouseholderOrthogonality(t *testing.T) {
+	// Gram-Schmidt loses orthogonality on the ill-conditioned Hilbert
+	// matrix, Householder does not
+	H := hilbert(8)
+	Q, _ := QR(H, QRHouseholder)
+	assertInDelta(t, Eye(8), Q.Transpose().Mul(Q), 1e-12)
+}
+
+func TestQRUpdate(t *testing.T) {
+	A := New(4, 3, 1, 2, 3, 4, 5, 6, 7, 8, 10, 1, 0, 1)
+	u := Vec(1, -1, 2, 0.5)
+	v := Vec(3, 0, -2)
+	Q, R := HouseholderQR(A, false)
+	Q1, R1 := QRUpdate(Q, R, u, v)
+	uv := u.Mul(v.Transpose())
+	for i := 1; i <= 4; i++ {
+		for j := 1; j <= 3; j++ {
+			uv.Set(i, j, uv.Get(i, j)+A.Get(i, j))
+		}
+	}
+	assertInDelta(t, uv, Q1.Mul(R1), 1e-12)
+	assertInDelta(t, Eye(4), Q1.Transpose().Mul(Q1), 1e-12)
+	for r := 2; r <= 4; r++ {
+		for c := 1; c < r && c <= 3; c++ {
+			assert.Equal(t, 0.0, R1.Get(r, c))
+		}
+	}
+}
+
+func TestOrthonormalize(t *testing.T) {
+	// Lauchli matrix, columns are nearly parallel
+	e := 1e-8
+	A := New(4, 3, 1, 1, 1, e, 0, 0, 0, e, 0, 0, 0, e)
+	tcs := []struct {
+		method GSMethod
+		reorth bool
+		loss   float64
+	}{
+		{method: ModifiedGS, reorth: false, loss: 1e-6},
+		{method: ClassicalGS, reorth: true, loss: 1e-12},
+		{method: ModifiedGS, reorth: true, loss: 1e-12},
+	}
+	for i, tc := range tcs {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			Q, err := Orthonormalize(A, tc.method, tc.reorth)
+			assert.NoError(t, err)
+			assertInDelta(t, Eye(3), Q.Transpose().Mul(Q), tc.loss)
+		})
+	}
+	// classical without reorthogonalization is visibly off
+	Q, err := Orthonormalize(A, ClassicalGS, false)
+	assert.NoError(t, err)
+	var dp float64
+	for k := 1; k <= 4; k++ {
+		dp += Q.Get(k, 2) * Q.Get(k, 3)
+	}
+	assert.True(t, math.Abs(dp) > 0.1)
+}
+
+func TestOrthonormalizeDependent(t *testing.T) {
+	A := New(3, 4, 1, 2, 0, 1, 0, 0, 1, 1, 1, 2, 0, 1)
+	_, err := Orthonormalize(A, ModifiedGS, false)
+	if assert.IsType(t, &DependentColumnsError{}, err) {
+		assert.Equal(t, []int{2, 4}, err.(*DependentColumnsError).Cols)
+	}
+	_, err = Orthonormalize(New(2, 2), ClassicalGS, true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package mat
+
+import "math"
+
+// EigenSym computes the eigenvalues and eigenvectors of symmetrical matrix
+// A using shifted QR iteration. Returns a column vector holding the
+// eigenvalues in ascending order and a matrix whose columns are the
+// corresponding unit eigenvectors. Panics if A is not square or not
+// symmetrical, returns error if the iteration does not converge within
+// maxIterations QR steps.
+func EigenSym(A *M, epsilon float64, maxIterations uint) (*M, *M, error) {
+	if A.rows != A.cols {
+		panic(errorf(ErrShape, "need square matrix for eigendecomposition"))
+	}
+	if !A.Equals(A.Transpose()) {
+		panic(errorf(ErrNotSymmetric, "need symmetrical matrix for symmetric eigendecomposition"))
+	}
+	T, V, err := qrIterate(A, epsilon, maxIterations)
+	if err != nil {
+		return nil, nil, err
+	}
+	vals := New(A.rows, 1)
+	for i := 1; i <= A.rows; i++ {
+		vals.Set(i, 1, T.Get(i, i))
+	}
+	sortEigen(vals, V)
+	return vals, V, nil
+}
+
+// Eigen computes the eigenvalues and eigenvectors of square matrix A using
+// shifted QR iteration. Returns a column vector holding the eigenvalues in
+// ascending order and a matrix whose columns are the corresponding unit
+// eigenvectors. Only real eigenvalues are supported: complex conjugate
+// pairs never separate, so they show up as a convergence error. Panics if
+// A is not square, returns error if the iteration does not converge within
+// maxIterations QR steps.
+func Eigen(A *M, epsilon float64, maxIterations uint) (*M, *M, error) {
+	if A.rows != A.cols {
+		panic(errorf(ErrShape, "need square matrix for eigendecomposition"))
+	}
+	T, Q, err := qrIterate(A, epsilon, maxIterations)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := A.rows
+	// T is upper triangular, so its eigenvectors follow from back
+	// substitution on (T - lambda*I)y = 0 with y(k) = 1
+	tiny := epsilon * frobenius(A)
+	if tiny == 0 {
+		tiny = epsilon
+	}
+	Y := New(n, n)
+	for k := 1; k <= n; k++ {
+		lambda := T.Get(k, k)
+		Y.Set(k, k, 1)
+		for i := k - 1; i >= 1; i-- {
+			var s float64
+			for j := i + 1; j <= k; j++ {
+				s += T.Get(i, j) * Y.Get(j, k)
+			}
+			d := T.Get(i, i) - lambda
+			if math.Abs(d) < tiny {
+				// repeated eigenvalue, perturb to keep going
+				d = tiny
+			}
+			Y.Set(i, k, -s/d)
+		}
+	}
+	V := Q.Mul(Y)
+	for j := 1; j <= n; j++ {
+		norm := colLength(V, j)
+		for i := 1; i <= n; i++ {
+			V.Set(i, j, V.Get(i, j)/norm)
+		}
+	}
+	vals := New(n, 1)
+	for i := 1; i <= n; i++ {
+		vals.Set(i, 1, T.Get(i, i))
+	}
+	sortEigen(vals, V)
+	return vals, V, nil
+}
+
+// qrIterate runs shifted QR iteration on A until it becomes upper
+// triangular, returning the triangular matrix T and the orthogonal matrix
+// V that accumulates all transformations, so that A = V*T*V'. Rows are
+// deflated from the bottom as soon as everything left of their diagonal
+// drops below epsilon relative to the size of A.
+func qrIterate(A *M, epsilon float64, maxIterations uint) (*M, *M, error) {
+	if epsilon < 0 {
+		panic(errorf(ErrArgument, "negative error margin"))
+	}
+	n := A.rows
+	T := A.Clone()
+	V := Eye(n)
+	tol := epsilon * frobenius(A)
+	var iterations uint
+	for hi := n; hi > 1; {
+		// check if the last active row has converged
+		var s float64
+		for j := 1; j < hi; j++ {
+			s = math.Max(s, math.Abs(T.Get(hi, j)))
+		}
+		if s <= tol {
+			for j := 1; j < hi; j++ {
+				T.Set(hi, j, 0)
+			}
+			hi--
+			continue
+		}
+		if iterations == maxIterations {
+			return nil, nil, errorf(ErrNotConverged, "iteration limit exceeded")
+		}
+		iterations++
+		// one QR step on the active block: B - mu*I = QR, B <- RQ + mu*I
+		mu := wilkinsonShift(T, hi)
+		B := T.View(1, 1, hi, hi)
+		d := B.Diag()
+		for i := 1; i <= hi; i++ {
+			d.Set(i, 1, d.Get(i, 1)-mu)
+		}
+		Q, R := HouseholderQR(B, false)
+		R.Mul(Q).CopyTo(B)
+		for i := 1; i <= hi; i++ {
+			d.Set(i, 1, d.Get(i, 1)+mu)
+		}
+		// keep the already deflated columns consistent with the transform
+		if hi < n {
+			C := T.View(1, hi+1, hi, n)
+			Q.Transpose().Mul(C).CopyTo(C)
+		}
+		W := V.View(1, 1, n, hi)
+		W.Mul(Q).CopyTo(W)
+	}
+	return T, V, nil
+}
+
+// wilkinsonShift returns the eigenvalue of the trailing 2 x 2 block of the
+// active part of T that is closest to T(hi, hi). Falls back to T(hi, hi)
+// when the block has complex eigenvalues.
+func wilkinsonShift(T *M, hi int) float64 {
+	a, b := T.Get(hi-1, hi-1), T.Get(hi-1, hi)
+	c, d := T.Get(hi, hi-1), T.Get(hi, hi)
+	h := (a - d) / 2
+	disc := h*h + b*c
+	if disc < 0 {
+		return d
+	}
+	// eigenvalues are d + h +/- r, pick the one nearest d while
+	// avoiding cancellation
+	r := math.Sqrt(disc)
+	if h < 0 {
+		r = -r
+	}
+	if h+r == 0 {
+		return d
+	}
+	return d - b*c/(h+r)
+}
+
+// sortEigen orders eigenvalues ascending, moving eigenvector columns along.
+func sortEigen(vals, vecs *M) {
+	for i := 1; i < vals.rows; i++ {
+		min := i
+		for j := i + 1; j <= vals.rows; j++ {
+			if vals.Get(j, 1) < vals.Get(min, 1) {
+				min = j
+			}
+		}
+		if min != i {
+			vals.SwapRows(i, min)
+			vecs.SwapCols(i, min)
+		}
+	}
+}
+
+func frobenius(A *M) float64 {
+	var s float64
+	for j := 1; j <= A.cols; j++ {
+		for i := 1; i <= A.rows; i++ {
+			s += A.Get(i, j) * A.Get(i, j)
+		}
+	}
+	return math.Sqrt(s)
+}
@@ -0,0 +1,67 @@
+package mat
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEigenSym(t *testing.T) {
+	tcs := []struct {
+		A    *M
+		vals *M
+	}{
+		{
+			A:    New(2, 2, 2, 1, 1, 2),
+			vals: Vec(1, 3),
+		},
+		{
+			A:    New(3, 3, 2, 0, 0, 0, 3, 4, 0, 4, 9),
+			vals: Vec(1, 2, 11),
+		},
+		{
+			A:    New(3, 3, 4, 1, -2, 1, 2, 0, -2, 0, 3),
+			vals: nil,
+		},
+	}
+	for i, tc := range tcs {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			vals, V, err := EigenSym(tc.A, 1e-12, 100)
+			assert.NoError(t, err)
+			if tc.vals != nil {
+				assertInDelta(t, tc.vals, vals, 1e-9)
+			}
+			for j := 1; j <= tc.A.cols; j++ {
+				v := V.Slice(1, j, V.rows, j)
+				Av := tc.A.Mul(v)
+				for k := 1; k <= v.rows; k++ {
+					v.Set(k, 1, v.Get(k, 1)*vals.Get(j, 1))
+				}
+				assertInDelta(t, v, Av, 1e-9)
+			}
+			assertInDelta(t, Eye(tc.A.rows), V.Transpose().Mul(V), 1e-9)
+		})
+	}
+}
+
+func TestEigen(t *testing.T) {
+	A := New(3, 3, 4, 1, 2, 2, 3, 0, 0, 0, 6)
+	vals, V, err := Eigen(A, 1e-12, 200)
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(2, 5, 6), vals, 1e-9)
+	for j := 1; j <= A.cols; j++ {
+		v := V.Slice(1, j, V.rows, j)
+		Av := A.Mul(v)
+		for k := 1; k <= v.rows; k++ {
+			v.Set(k, 1, v.Get(k, 1)*vals.Get(j, 1))
+		}
+		assertInDelta(t, v, Av, 1e-9)
+	}
+}
+
+func TestEigenComplexDoesNotConverge(t *testing.T) {
+	A := New(2, 2, 0, -1, 1, 0)
+	_, _, err := Eigen(A, 1e-12, 50)
+	assert.Error(t, err)
+}
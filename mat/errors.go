@@ -0,0 +1,60 @@
+package mat
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of errors returned, or panicked with, by package mat. Errors match
+// their kind with errors.Is, e.g. errors.Is(err, ErrSingular).
+var (
+	// ErrShape means matrices have the wrong or mismatched sizes.
+	ErrShape = errors.New("wrong shape")
+	// ErrIndex means a row or column index is out of range.
+	ErrIndex = errors.New("index out of range")
+	// ErrArgument means an argument other than a matrix is out of range.
+	ErrArgument = errors.New("invalid argument")
+	// ErrNotSymmetric means a symmetric matrix was required.
+	ErrNotSymmetric = errors.New("matrix not symmetric")
+	// ErrSingular means a matrix is singular, or does not have full rank.
+	ErrSingular = errors.New("matrix is singular")
+	// ErrInconsistent means a linear equation system has no solution.
+	ErrInconsistent = errors.New("inconsistent system")
+	// ErrIllConditioned means a matrix exceeds the requested condition
+	// number limit.
+	ErrIllConditioned = errors.New("matrix is ill-conditioned")
+	// ErrNotPositiveDefinite means a positive definite matrix was
+	// required.
+	ErrNotPositiveDefinite = errors.New("matrix not positive definite")
+	// ErrNotConverged means an iterative method did not converge.
+	ErrNotConverged = errors.New("not converged")
+)
+
+// Error is the type of errors returned by package mat that don't have a
+// more specific type, and of the values it panics with on invalid input.
+// It keeps the detailed message while matching one of the Err values.
+type Error struct {
+	// Kind is one of the Err values.
+	Kind error
+	msg  string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the kind of e, so errors.Is can match it.
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Errorf formats an *Error of the given kind, so packages building on mat
+// can report errors the same way.
+func Errorf(kind error, format string, a ...interface{}) error {
+	return errorf(kind, format, a...)
+}
+
+// errorf formats an *Error of the given kind.
+func errorf(kind error, format string, a ...interface{}) error {
+	return &Error{Kind: kind, msg: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,56 @@
+package mat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKinds(t *testing.T) {
+	tcs := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"singular LU", second(FactorLU(New(2, 2, 1, 2, 2, 4))), ErrSingular},
+		{"underdetermined", second(SolveGaussPartial(New(2, 2, 1, 2, 2, 4), Vec(1, 2))), ErrSingular},
+		{"inconsistent", second(SolveGaussPartial(New(2, 2, 1, 2, 2, 4), Vec(1, 3))), ErrInconsistent},
+		{"not positive definite", second(FactorCholesky(New(2, 2, 1, 2, 2, 1))), ErrNotPositiveDefinite},
+		{"singular inverse", second(GaussJordan(New(2, 2, 1, 2, 2, 4))), ErrSingular},
+		{"ill-conditioned", second(SolveGaussPartial(hilbert(8), ones(8), WithCondLimit(1e3))), ErrIllConditioned},
+		{"dependent columns", second(Orthonormalize(New(2, 2, 1, 2, 1, 2), ModifiedGS, false)), ErrSingular},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.True(t, errors.Is(tc.err, tc.kind), "%v", tc.err)
+		})
+	}
+
+	_, _, err := SolveJacobi(New(2, 2, 1, 2, 2, 1), Vec(1, 1), Vec(0, 0), 1e-9, 10)
+	assert.True(t, errors.Is(err, ErrNotConverged))
+	var merr *Error
+	assert.True(t, errors.As(err, &merr))
+}
+
+func TestPanicsWithError(t *testing.T) {
+	defer func() {
+		err, ok := recover().(*Error)
+		if assert.True(t, ok) {
+			assert.True(t, errors.Is(err, ErrIndex))
+			assert.EqualError(t, err, "invalid matrix indices: 3, 1")
+		}
+	}()
+	Eye(2).Get(3, 1)
+}
+
+// second returns the error from a two value call.
+func second(_ interface{}, err error) error {
+	return err
+}
+
+func TestErrorf(t *testing.T) {
+	err := Errorf(ErrShape, "bad shape %d x %d", 2, 3)
+	assert.True(t, errors.Is(err, ErrShape))
+	assert.EqualError(t, err, "bad shape 2 x 3")
+}
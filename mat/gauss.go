@@ -1,33 +1,117 @@
 package mat
 
-import "fmt"
+import "math"
 
 // SolveUpper solves a linear equation system that has been
 // converted to upper triangular form. A is the augmented
-// system matrix, i.e. it will have m rows and m+1 columns.
+// system matrix, i.e. it will have m rows and m+k columns,
+// one for each right hand side, and the solution has k columns.
 // Panics if A is the wrong size, returns error if the system
-// is not properly determined.
+// does not have a single solution, telling apart inconsistent and
+// underdetermined systems. Diagonal elements no larger than a tolerance
+// based on the size and largest coefficient of the system count as
+// zeroes. See SolveGeneral for the solutions of the
+// latter, and SolveTriangular for solving with a separate right hand
+// side.
 func SolveUpper(A *M) (*M, error) {
-	if A.cols != A.rows+1 {
-		panic("matrix must be of size m/m+1")
+	if A.cols <= A.rows {
+		panic(errorf(ErrShape, "matrix must be of size m/m+k"))
 	}
-	if almostEqual(A.Get(A.rows, A.cols-1), 0) {
-		if almostEqual(A.Get(A.rows, A.cols), 0) {
-			return nil, fmt.Errorf("undertermined system")
+	m := A.rows
+	tol := rankTolerance(A.View(1, 1, m, m))
+	for i := 1; i <= m; i++ {
+		if math.Abs(A.Get(i, i)) > tol {
+			continue
 		}
-		return nil, fmt.Errorf("overdertermined system")
+		return nil, noUniqueSolution(A.View(1, 1, m, m), A.View(1, m+1, m, A.cols))
 	}
-	x := New(A.rows, 1)
-	for i := A.rows; i >= 1; i-- {
-		var s float64
-		for j := A.cols - 1; j > i; j-- {
-			s += A.Get(i, j) * x.Get(j, 1)
+	x := New(m, A.cols-m)
+	for c := 1; c <= x.cols; c++ {
+		for i := m; i >= 1; i-- {
+			var s float64
+			for j := m; j > i; j-- {
+				s += A.Get(i, j) * x.Get(j, c)
+			}
+			x.Set(i, c, (A.Get(i, m+c)-s)/A.Get(i, i))
 		}
-		x.Set(i, 1, (A.Get(i, A.cols)-s)/A.Get(i, i))
 	}
 	return x, nil
 }
 
+// TriFlag selects how SolveTriangular reads its triangular matrix. Flags
+// can be combined with |.
+type TriFlag int
+
+// Flags accepted by SolveTriangular.
+const (
+	// TriUpper uses the upper triangle of T, the lower one is used
+	// otherwise.
+	TriUpper TriFlag = 1 << iota
+	// TriUnit assumes ones on the diagonal of T, without reading it.
+	TriUnit
+	// TriTrans solves T'X = B instead of TX = B.
+	TriTrans
+)
+
+// SolveLower solves LX = B by forward substitution, where L is lower
+// triangular and B may have any number of columns. Only the lower
+// triangle of L is read. Panics if L is not square or B does not have
+// as many rows as L. Returns error if L has a zero on the diagonal.
+func SolveLower(L, B *M) (*M, error) {
+	return SolveTriangular(L, B, 0)
+}
+
+// SolveTriangular solves TX = B, where T is triangular and B may have any
+// number of columns. The elements on the other side of the diagonal are
+// never read, so packed factorizations can be passed directly. Panics if
+// T is not square or B does not have as many rows as T. Returns error if
+// T has a zero on the diagonal.
+func SolveTriangular(T, B *M, flags TriFlag) (*M, error) {
+	if T.rows != T.cols {
+		panic(errorf(ErrShape, "triangular solver only works on square matrices"))
+	}
+	if B.rows != T.rows {
+		panic(errorf(ErrShape, "B matrix has the wrong shape: %d x %d", B.rows, B.cols))
+	}
+	if flags&TriUnit == 0 {
+		for i := 1; i <= T.rows; i++ {
+			if T.Get(i, i) == 0 {
+				return nil, errorf(ErrSingular, "zero on diagonal at %d, %d", i, i)
+			}
+		}
+	}
+	return triSolve(T, B, flags), nil
+}
+
+// triSolve is SolveTriangular without any checks.
+func triSolve(T, B *M, flags TriFlag) *M {
+	n := T.rows
+	get := T.Get
+	if flags&TriTrans != 0 {
+		get = func(i, j int) float64 { return T.Get(j, i) }
+	}
+	// an upper triangle read transposed is lower, and the other way round
+	forward := (flags&TriUpper == 0) == (flags&TriTrans == 0)
+	X := B.Clone()
+	for c := 1; c <= X.cols; c++ {
+		for k := 1; k <= n; k++ {
+			i, lo, hi := k, 1, k-1
+			if !forward {
+				i, lo, hi = n+1-k, n+2-k, n
+			}
+			s := X.Get(i, c)
+			for j := lo; j <= hi; j++ {
+				s -= get(i, j) * X.Get(j, c)
+			}
+			if flags&TriUnit == 0 {
+				s /= get(i, i)
+			}
+			X.Set(i, c, s)
+		}
+	}
+	return X
+}
+
 // GaussSimple tries to bring a matrix to row echelon form with no
 // pivoting at all. Can fail if matrix has zeroes on diagonal.
 func GaussSimple(a *M) error {
@@ -35,7 +119,7 @@ func GaussSimple(a *M) error {
 	for pivotRow <= a.rows && pivotCol <= a.cols {
 		pivot := a.Get(pivotRow, pivotCol)
 		if almostEqual(pivot, 0) {
-			return fmt.Errorf("zero on diagonal")
+			return errorf(ErrSingular, "zero on diagonal")
 		}
 		// transform all elements below pivot to zeroes
 		for i := pivotRow + 1; i <= a.rows; i++ {
@@ -52,17 +136,29 @@ func GaussSimple(a *M) error {
 }
 
 // GaussPartialPivot brings a matrix to row echelon form by only pivoting
-// rows.
+// rows. Columns with no element larger than a tolerance based on the size
+// and largest element of a get no pivot. See RREF for the reduced row
+// echelon form.
 func GaussPartialPivot(a *M) {
+	gaussPartialPivot(a, a.cols, rankTolerance(a))
+}
+
+// gaussPartialPivot brings a matrix to row echelon form by only pivoting
+// rows, looking for pivots in the first n columns only and treating
+// values no larger than tol in absolute value as zeroes. Returns the
+// pivot columns, one for each non-zero row.
+func gaussPartialPivot(a *M, n int, tol float64) []int {
+	var pivots []int
 	pivotRow, pivotCol := 1, 1
-	for pivotRow <= a.rows && pivotCol <= a.cols {
+	for pivotRow <= a.rows && pivotCol <= n {
 		// look for pivots in current column
 		rmax, _ := a.MaxIndex(pivotRow, pivotCol, a.rows, pivotCol)
-		if almostEqual(a.Get(rmax, pivotCol), 0) {
+		if math.Abs(a.Get(rmax, pivotCol)) <= tol {
 			// no pivot, move to next column
 			pivotCol++
 			continue
 		}
+		pivots = append(pivots, pivotCol)
 		a.SwapRows(rmax, pivotRow)
 		pivot := a.Get(pivotRow, pivotCol)
 		// transform all elements below pivot to zeroes
@@ -76,21 +172,24 @@ func GaussPartialPivot(a *M) {
 		pivotRow++
 		pivotCol++
 	}
+	return pivots
 }
 
-// GaussFullPivot brings a matrix to row echelon form by only pivoting
-// rows. Panics if a is not an augmented system matrix. Retuns a permutation
-// matrix that can be applied to a x vector to regain initial order.
+// GaussFullPivot brings a matrix to row echelon form by pivoting both
+// rows and columns. Panics if a is not an augmented system matrix, with
+// m rows and m+k columns. Pivots are only taken from the first m columns.
+// Retuns a permutation matrix that can be applied to a solution to regain
+// initial order.
 func GaussFullPivot(a *M) *M {
-	if a.cols != a.rows+1 {
-		panic("matrix must have shape m/m+1")
+	if a.cols <= a.rows {
+		panic(errorf(ErrShape, "matrix must have shape m/m+k"))
 	}
 	pivotRow, pivotCol := 1, 1
 	perm := Eye(a.rows)
 	for pivotRow <= a.rows && pivotCol <= a.cols {
-		// look for pivots in current column
-		rmax, cmax := a.MaxIndex(pivotRow, pivotCol, a.rows, a.cols-1)
-		if almostEqual(a.Get(rmax, pivotCol), 0) {
+		// look for pivots in the remaining coefficients
+		rmax, cmax := a.MaxIndex(pivotRow, pivotCol, a.rows, a.rows)
+		if almostEqual(a.Get(rmax, cmax), 0) {
 			// no pivot at all, we're done, rest is zeroes
 			return perm
 		}
@@ -112,58 +211,175 @@ func GaussFullPivot(a *M) *M {
 	return perm
 }
 
-// SolveGaussSimple solves the linear equation system  Ax = b
+// SolveGaussSimple solves the linear equation system  AX = B
 // by gaussian elimination with no pivoting. Panics if A
-// is not a square matrix or b is not a vector the size of A.
+// is not a square matrix or B does not have as many rows as A.
+// B may have any number of columns, all of them are solved with a
+// single elimination.
 // Returns error if system does not have a single solution or
 // gaussian elimination cannot proceed (zeroes on diagonal).
-func SolveGaussSimple(A, b *M) (*M, error) {
+// See WithCondLimit for detecting ill-conditioned systems.
+func SolveGaussSimple(A, B *M, opts ...SolveOption) (*M, error) {
 	if A.rows != A.cols {
-		panic("gaussian solver only works on square matrices")
+		panic(errorf(ErrShape, "gaussian solver only works on square matrices"))
 	}
-	if b.rows != A.rows {
-		panic("free term vector must be the same size as A")
+	if B.rows != A.rows {
+		panic(errorf(ErrShape, "free terms must have as many rows as A"))
 	}
-	a := A.Augment(b)
+	a := A.Augment(B)
 	err := GaussSimple(a)
 	if err != nil {
 		return nil, err
 	}
-	return SolveUpper(a)
+	x, err := SolveUpper(a)
+	if err != nil {
+		return nil, err
+	}
+	return x, checkCond(A, opts)
 }
 
-// SolveGaussPartial solves the linear equation system  Ax = b
+// SolveGaussPartial solves the linear equation system  AX = B
 // by gaussian elimination with partial pivoting. Panics if A
-// is not a square matrix or b is not a vector the size of A.
+// is not a square matrix or B does not have as many rows as A.
+// B may have any number of columns, all of them are solved with a
+// single elimination.
 // Returns error if system does not have a single solution.
-func SolveGaussPartial(A, b *M) (*M, error) {
+// See WithCondLimit for detecting ill-conditioned systems.
+func SolveGaussPartial(A, B *M, opts ...SolveOption) (*M, error) {
 	if A.rows != A.cols {
-		panic("gaussian solver only works on square matrices")
+		panic(errorf(ErrShape, "gaussian solver only works on square matrices"))
 	}
-	if b.rows != A.rows {
-		panic("free term vector must be the same size as A")
+	if B.rows != A.rows {
+		panic(errorf(ErrShape, "free terms must have as many rows as A"))
 	}
-	a := A.Augment(b)
-	GaussPartialPivot(a)
-	return SolveUpper(a)
+	a := A.Augment(B)
+	gaussPartialPivot(a, A.cols, rankTolerance(A))
+	x, err := SolveUpper(a)
+	if err != nil {
+		return nil, err
+	}
+	return x, checkCond(A, opts)
 }
 
-// SolveGaussFull solves the linear equation system  Ax = b
+// SolveGaussFull solves the linear equation system  AX = B
 // by gaussian elimination with full pivoting. Panics if A
-// is not a square matrix or b is not a vector the size of A.
+// is not a square matrix or B does not have as many rows as A.
+// B may have any number of columns, all of them are solved with a
+// single elimination.
 // Returns error if system does not have a single solution.
-func SolveGaussFull(A, b *M) (*M, error) {
+// See WithCondLimit for detecting ill-conditioned systems.
+func SolveGaussFull(A, B *M, opts ...SolveOption) (*M, error) {
 	if A.rows != A.cols {
-		panic("gaussian solver only works on square matrices")
+		panic(errorf(ErrShape, "gaussian solver only works on square matrices"))
 	}
-	if b.rows != A.rows {
-		panic("free term vector must be the same size as A")
+	if B.rows != A.rows {
+		panic(errorf(ErrShape, "free terms must have as many rows as A"))
 	}
-	a := A.Augment(b)
+	a := A.Augment(B)
 	perm := GaussFullPivot(a)
 	x1, err := SolveUpper(a)
 	if err != nil {
-		return nil, err
+		// report free variables in the original order
+		return nil, noUniqueSolution(A, B)
+	}
+	return perm.Mul(x1), checkCond(A, opts)
+}
+
+// noUniqueSolution tells why AX = B does not have a single solution,
+// knowing that A is singular: either one of the columns of B is
+// inconsistent, or there are free variables, which only depend on A.
+func noUniqueSolution(A, B *M) error {
+	tol := rankTolerance(A)
+	for c := 1; c <= B.cols; c++ {
+		if _, err := SolveGeneral(A, B.Col(c), tol); err != nil {
+			return err
+		}
+	}
+	pivots := gaussPartialPivot(A.Clone(), A.cols, tol)
+	return errorf(ErrSingular, "underdetermined system, rank %d, free variables %v", len(pivots), freeColumns(pivots, A.cols))
+}
+
+// freeColumns lists the columns from 1 to n that are not in pivots, in
+// increasing order.
+func freeColumns(pivots []int, n int) []int {
+	isPivot := make([]bool, n+1)
+	for _, p := range pivots {
+		isPivot[p] = true
+	}
+	var free []int
+	for j := 1; j <= n; j++ {
+		if !isPivot[j] {
+			free = append(free, j)
+		}
+	}
+	return free
+}
+
+// Solution is the general solution of a linear equation system Ax = b:
+// every x = Particular + NullSpace * t, for any vector t, solves it.
+type Solution struct {
+	// Particular is the solution with all free variables set to zero.
+	Particular *M
+	// NullSpace has a basis of the null space of A as columns, one for
+	// each free variable, or is nil if the solution is unique.
+	NullSpace *M
+	// Free lists the free variables, in increasing order.
+	Free []int
+	// Rank is the rank of A.
+	Rank int
+}
+
+// SolveGeneral finds the general solution of the linear equation system
+// Ax = b, with A of any shape, by gaussian elimination with partial
+// pivoting. Pivots no larger than tol in absolute value are considered
+// zero; a tol <= 0 selects a default based on the size and largest
+// element of A and b. Panics if b is not a vector with as many rows as A.
+// Returns error if the system is inconsistent, i.e. has no solution.
+func SolveGeneral(A, b *M, tol float64) (*Solution, error) {
+	if b.cols != 1 || b.rows != A.rows {
+		panic(errorf(ErrShape, "b vector has the wrong shape: %d x %d", b.rows, b.cols))
+	}
+	a := A.Augment(b)
+	if tol <= 0 {
+		tol = rankTolerance(a)
+	}
+	n := A.cols
+	pivots := gaussPartialPivot(a, n, tol)
+	rank := len(pivots)
+	for i := rank + 1; i <= a.rows; i++ {
+		if math.Abs(a.Get(i, n+1)) > tol {
+			return nil, errorf(ErrInconsistent, "inconsistent system, equation %d cannot be satisfied", i)
+		}
+	}
+	s := &Solution{Particular: New(n, 1), Rank: rank, Free: freeColumns(pivots, n)}
+	backSubstitute(a, pivots, s.Particular, true)
+	if len(s.Free) > 0 {
+		s.NullSpace = New(n, len(s.Free))
+		for k, f := range s.Free {
+			x := New(n, 1)
+			x.Set(f, 1, 1)
+			backSubstitute(a, pivots, x, false)
+			for i := 1; i <= n; i++ {
+				s.NullSpace.Set(i, k+1, x.Get(i, 1))
+			}
+		}
+	}
+	return s, nil
+}
+
+// backSubstitute solves for the pivot variables of an augmented system
+// in row echelon form, with the free variables already set in x. The right
+// hand side is only used if rhs is true, otherwise it is taken as zero.
+func backSubstitute(a *M, pivots []int, x *M, rhs bool) {
+	for r := len(pivots); r >= 1; r-- {
+		p := pivots[r-1]
+		var s float64
+		if rhs {
+			s = a.Get(r, a.cols)
+		}
+		for j := p + 1; j < a.cols; j++ {
+			s -= a.Get(r, j) * x.Get(j, 1)
+		}
+		x.Set(p, 1, s/a.Get(r, p))
 	}
-	return perm.Mul(x1), nil
 }
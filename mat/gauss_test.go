@@ -1,6 +1,7 @@
 package mat
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,3 +29,155 @@ func TestGaussFullPivots(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, x.Equals(Vec(2, 3, -1)))
 }
+
+func TestSolveTriangular(t *testing.T) {
+	L := New(3, 3, 2, 0, 0, 1, 3, 0, -1, 2, 4)
+	U := L.Transpose()
+	X := New(3, 2, 1, -1, 2, 0, 3, 5)
+	// garbage in the unused triangle must be ignored
+	packed := L.Clone()
+	packed.Set(1, 3, 100)
+	packed.Set(2, 3, -7)
+
+	tcs := []struct {
+		name  string
+		T     *M
+		A     *M
+		flags TriFlag
+	}{
+		{"lower", packed, L, 0},
+		{"upper", U, U, TriUpper},
+		{"lower transposed", packed, U, TriTrans},
+		{"upper transposed", U, L, TriUpper | TriTrans},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SolveTriangular(tc.T, tc.A.Mul(X), tc.flags)
+			assert.NoError(t, err)
+			assertInDelta(t, X, got, 1e-12)
+		})
+	}
+
+	// unit diagonal is assumed, not read
+	L1 := New(2, 2, 1, 0, 3, 1)
+	x, err := SolveTriangular(New(2, 2, 0, 0, 3, 0), L1.Mul(Vec(1, 2)), TriUnit)
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(1, 2), x, 0)
+
+	x, err = SolveLower(L, L.Mul(Vec(1, 2, 3)))
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(1, 2, 3), x, 1e-12)
+
+	_, err = SolveLower(New(2, 2, 1, 0, 1, 0), Vec(1, 1))
+	assert.Error(t, err)
+	assert.Panics(t, func() { SolveLower(L, Vec(1, 2)) })
+	assert.Panics(t, func() { SolveTriangular(New(2, 3), Vec(1, 2), 0) })
+}
+
+func TestSolveGeneral(t *testing.T) {
+	tcs := []struct {
+		name string
+		A, b *M
+		rank int
+		free []int
+	}{
+		{"unique", New(3, 3, 2, 1, -1, -3, -1, 2, -2, 1, 2), Vec(8, -11, -3), 3, nil},
+		{"singular square", New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9), Vec(6, 15, 24), 2, []int{3}},
+		{"wide", New(2, 4, 1, 2, 0, 1, 0, 0, 1, 1), Vec(3, 2), 2, []int{2, 4}},
+		{"tall", New(3, 2, 1, 1, 1, -1, 2, 0), Vec(2, 0, 2), 2, nil},
+		{"zero column first", New(2, 3, 0, 1, 1, 0, 2, 3), Vec(1, 2), 2, []int{1}},
+		{"zero matrix", New(2, 2), Vec(0, 0), 0, []int{1, 2}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := SolveGeneral(tc.A, tc.b, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.rank, s.Rank)
+			assert.Equal(t, tc.free, s.Free)
+			assertInDelta(t, tc.b, tc.A.Mul(s.Particular), 1e-12)
+			if tc.free == nil {
+				assert.Nil(t, s.NullSpace)
+				return
+			}
+			_, cols := s.NullSpace.Dims()
+			assert.Equal(t, len(tc.free), cols)
+			assertInDelta(t, New(tc.A.rows, cols), tc.A.Mul(s.NullSpace), 1e-12)
+			// any combination of the null space added to x still solves it
+			x := s.Particular.Clone()
+			for i := 1; i <= x.rows; i++ {
+				for j := 1; j <= cols; j++ {
+					x.Set(i, 1, x.Get(i, 1)+float64(j+1)*s.NullSpace.Get(i, j))
+				}
+			}
+			assertInDelta(t, tc.b, tc.A.Mul(x), 1e-12)
+		})
+	}
+
+	_, err := SolveGeneral(New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9), Vec(1, 1, 2), 0)
+	assert.Error(t, err)
+	_, err = SolveGeneral(New(3, 2, 1, 1, 1, -1, 2, 0), Vec(2, 0, 3), 0)
+	assert.Error(t, err)
+	assert.Panics(t, func() { SolveGeneral(Eye(2), Vec(1, 2, 3), 0) })
+}
+
+func TestSolveUpperErrors(t *testing.T) {
+	// second equation reads 0 = 1
+	_, err := SolveUpper(New(2, 3, 1, 1, 2, 0, 0, 1))
+	assert.EqualError(t, err, "inconsistent system, equation 2 cannot be satisfied")
+	_, err = SolveUpper(New(2, 3, 1, 1, 2, 0, 0, 0))
+	assert.EqualError(t, err, "underdetermined system, rank 1, free variables [2]")
+	// only the middle diagonal element is zero
+	_, err = SolveUpper(New(3, 4, 1, 1, 1, 3, 0, 0, 1, 1, 0, 0, 1, 1))
+	assert.EqualError(t, err, "underdetermined system, rank 2, free variables [2]")
+	_, err = SolveGaussPartial(New(2, 2, 1, 2, 2, 4), Vec(1, 1))
+	assert.Error(t, err)
+
+	// singular only up to rounding, the last pivot is about 1e-16
+	A := New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	for _, solve := range []func(A, B *M, opts ...SolveOption) (*M, error){SolveGaussPartial, SolveGaussFull} {
+		_, err = solve(A, Vec(1, 2, 4))
+		assert.True(t, errors.Is(err, ErrInconsistent), "%v", err)
+		_, err = solve(A, Vec(1, 2, 3))
+		assert.EqualError(t, err, "underdetermined system, rank 2, free variables [3]")
+	}
+}
+
+func TestGaussManyRightHandSides(t *testing.T) {
+	A := New(3, 3, 2, 1, -1, -3, -1, 2, -2, 1, 2)
+	X := New(3, 4, 2, 1, 0, -1, 3, 0, 1, 2, -1, 2, 0, 5)
+	B := A.Mul(X)
+	solvers := map[string]func(A, B *M, opts ...SolveOption) (*M, error){
+		"simple":  SolveGaussSimple,
+		"partial": SolveGaussPartial,
+		"full":    SolveGaussFull,
+	}
+	for name, solve := range solvers {
+		t.Run(name, func(t *testing.T) {
+			got, err := solve(A, B)
+			assert.NoError(t, err)
+			assertInDelta(t, X, got, 1e-12)
+		})
+	}
+
+	x, err := SolveUpper(New(2, 4, 2, 1, 4, 1, 0, 1, 1, 2))
+	assert.NoError(t, err)
+	assertInDelta(t, New(2, 2, 1.5, -0.5, 1, 2), x, 1e-12)
+	// only the second right hand side is inconsistent
+	_, err = SolveUpper(New(2, 4, 1, 1, 2, 2, 0, 0, 0, 1))
+	assert.EqualError(t, err, "inconsistent system, equation 2 cannot be satisfied")
+	// the free variables come from A, whatever the last right hand side
+	_, err = SolveGaussPartial(New(2, 2, 1, 1, 2, 2), New(2, 2, 1, 0, 2, 0))
+	assert.EqualError(t, err, "underdetermined system, rank 1, free variables [2]")
+	assert.Panics(t, func() { SolveUpper(Eye(2)) })
+	assert.Panics(t, func() { GaussFullPivot(Eye(2)) })
+}
+
+func TestGaussFullPivotZeroFirstColumn(t *testing.T) {
+	// the largest element is not in the current column
+	x, err := SolveGaussFull(New(2, 2, 0, 1, 0, 2), Vec(1, 2))
+	assert.EqualError(t, err, "underdetermined system, rank 1, free variables [1]")
+	assert.Nil(t, x)
+	x, err = SolveGaussFull(New(2, 2, 0, 1, 1, 2), Vec(1, 2))
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(0, 1), x, 1e-12)
+}
@@ -0,0 +1,66 @@
+package generic
+
+import "github.com/rciurlea/cn/mat"
+
+// LU computes the LU decomposition of square matrix A with no pivoting,
+// returning unit lower triangular L and upper triangular U. Panics if A
+// is not square. Returns error if a pivot no larger than a tolerance based
+// on the size, largest element and precision of A is found, see PLU.
+func LU[T Scalar](A *M[T]) (*M[T], *M[T], error) {
+	if A.cols != A.rows {
+		panic(mat.Errorf(mat.ErrShape, "matrix must be square for LU decomposition"))
+	}
+	U := A.Clone()
+	L := Eye[T](A.rows)
+	tol := tolerance(A, A.rows, A.cols)
+	for k := 1; k <= U.rows; k++ {
+		pivot := U.Get(k, k)
+		if abs(pivot) <= tol {
+			return nil, nil, mat.Errorf(mat.ErrSingular, "zero pivot found at %d, %d", k, k)
+		}
+		// save scaling factors to L before creating zeroes below pivot
+		for i := k + 1; i <= U.rows; i++ {
+			L.Set(i, k, U.Get(i, k)/pivot)
+		}
+		eliminate(U, k, k)
+	}
+	return L, U, nil
+}
+
+// PLU computes the LU decomposition of square matrix A with partial
+// pivoting, PA = LU, returning permutation matrix P, unit lower triangular
+// L and upper triangular U. A is not mutated. Panics if A is not square.
+// Returns error if A is singular, i.e. a pivot is no larger than a
+// tolerance based on the size, largest element and precision of A.
+func PLU[T Scalar](A *M[T]) (*M[T], *M[T], *M[T], error) {
+	if A.cols != A.rows {
+		panic(mat.Errorf(mat.ErrShape, "matrix must be square for LU decomposition"))
+	}
+	n := A.rows
+	U := A.Clone()
+	L := Eye[T](n)
+	P := Eye[T](n)
+	tol := tolerance(A, n, n)
+	for k := 1; k <= n; k++ {
+		rmax, _ := U.MaxIndex(k, k, n, k)
+		if abs(U.Get(rmax, k)) <= tol {
+			return nil, nil, nil, mat.Errorf(mat.ErrSingular, "zero pivot found at %d, %d", k, k)
+		}
+		if rmax != k {
+			U.SwapRows(rmax, k)
+			P.SwapRows(rmax, k)
+			// factors already in L move along with their rows
+			for j := 1; j < k; j++ {
+				a, b := L.Get(rmax, j), L.Get(k, j)
+				L.Set(rmax, j, b)
+				L.Set(k, j, a)
+			}
+		}
+		pivot := U.Get(k, k)
+		for i := k + 1; i <= n; i++ {
+			L.Set(i, k, U.Get(i, k)/pivot)
+		}
+		eliminate(U, k, k)
+	}
+	return P, L, U, nil
+}
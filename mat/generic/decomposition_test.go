@@ -0,0 +1,50 @@
+package generic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rciurlea/cn/mat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLU(t *testing.T) {
+	A := New[complex128](3, 3, 4, 1i, 2, -1i, 3, 1, 2, 1, 5+1i)
+	L, U, err := LU(A)
+	assert.NoError(t, err)
+	assertInDelta(t, A, L.Mul(U), 1e-12)
+	for r := 1; r <= 3; r++ {
+		assert.Equal(t, complex128(1), L.Get(r, r))
+		for c := r + 1; c <= 3; c++ {
+			assert.Equal(t, complex128(0), L.Get(r, c))
+			assert.Equal(t, complex128(0), U.Get(c, r))
+		}
+	}
+	_, _, err = LU(New[complex128](2, 2, 0, 1, 1, 0))
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	assert.Panics(t, func() { LU(New[complex128](2, 3)) })
+}
+
+func TestPLU(t *testing.T) {
+	tcs := []*M[complex128]{
+		New[complex128](2, 2, 0, 1i, 1, 0),
+		New[complex128](3, 3, 1, 2i, 3, 4, 5, 6i, 7, 8, 10),
+		New[complex128](3, 3, 0, 0, 2i, 0, 3, 0, 4, 0, 0),
+	}
+	for _, A := range tcs {
+		P, L, U, err := PLU(A)
+		assert.NoError(t, err)
+		assertInDelta(t, P.Mul(A), L.Mul(U), 1e-12)
+	}
+	_, _, _, err := PLU(New[complex128](2, 2, 1, 1i, 1i, -1))
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	_, _, _, err = PLU(New[complex128](3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9))
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	_, _, err = LU(New[float32](3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9))
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+
+	A := New[float32](3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 10)
+	P, L, U, err := PLU(A)
+	assert.NoError(t, err)
+	assertInDelta(t, P.Mul(A), L.Mul(U), 1e-5)
+}
@@ -0,0 +1,184 @@
+package generic
+
+import "github.com/rciurlea/cn/mat"
+
+// SolveUpper solves a linear equation system that has been
+// converted to upper triangular form. A is the augmented
+// system matrix, i.e. it will have m rows and m+k columns,
+// one for each right hand side, and the solution has k columns.
+// Panics if A is the wrong size, returns error if there is a
+// zero on the diagonal. Diagonal elements no larger than a tolerance
+// based on the size, largest coefficient and precision of the system
+// count as zeroes.
+func SolveUpper[T Scalar](A *M[T]) (*M[T], error) {
+	if A.cols <= A.rows {
+		panic(mat.Errorf(mat.ErrShape, "matrix must be of size m/m+k"))
+	}
+	m := A.rows
+	tol := tolerance(A, m, m)
+	for i := 1; i <= m; i++ {
+		if abs(A.Get(i, i)) <= tol {
+			return nil, mat.Errorf(mat.ErrSingular, "system does not have a single solution, zero on diagonal at %d, %d", i, i)
+		}
+	}
+	x := New[T](m, A.cols-m)
+	for c := 1; c <= x.cols; c++ {
+		for i := m; i >= 1; i-- {
+			var s T
+			for j := m; j > i; j-- {
+				s += A.Get(i, j) * x.Get(j, c)
+			}
+			x.Set(i, c, (A.Get(i, m+c)-s)/A.Get(i, i))
+		}
+	}
+	return x, nil
+}
+
+// GaussSimple tries to bring a matrix to row echelon form with no
+// pivoting at all. Can fail if matrix has zeroes on diagonal, or
+// elements no larger than a tolerance based on its size, largest element
+// and precision.
+func GaussSimple[T Scalar](a *M[T]) error {
+	tol := tolerance(a, a.rows, a.cols)
+	for k := 1; k <= a.rows && k <= a.cols; k++ {
+		if abs(a.Get(k, k)) <= tol {
+			return mat.Errorf(mat.ErrSingular, "zero on diagonal")
+		}
+		eliminate(a, k, k)
+	}
+	return nil
+}
+
+// GaussPartialPivot brings a matrix to row echelon form by only pivoting
+// rows. Pivots are the elements of largest modulus; columns with no
+// element larger than a tolerance based on the size, largest element and
+// precision of a get no pivot.
+func GaussPartialPivot[T Scalar](a *M[T]) {
+	gaussPartialPivot(a, a.cols, tolerance(a, a.rows, a.cols))
+}
+
+// gaussPartialPivot brings a matrix to row echelon form by only pivoting
+// rows, looking for pivots in the first n columns only and treating
+// values no larger than tol in modulus as zeroes. Returns the number of
+// pivots found.
+func gaussPartialPivot[T Scalar](a *M[T], n int, tol float64) int {
+	pivotRow, pivotCol := 1, 1
+	for pivotRow <= a.rows && pivotCol <= n {
+		// look for pivots in current column
+		rmax, _ := a.MaxIndex(pivotRow, pivotCol, a.rows, pivotCol)
+		if abs(a.Get(rmax, pivotCol)) <= tol {
+			// no pivot, move to next column
+			pivotCol++
+			continue
+		}
+		a.SwapRows(rmax, pivotRow)
+		eliminate(a, pivotRow, pivotCol)
+		pivotRow++
+		pivotCol++
+	}
+	return pivotRow - 1
+}
+
+// GaussFullPivot brings a matrix to row echelon form by pivoting both
+// rows and columns. Panics if a is not an augmented system matrix, with
+// m rows and m+k columns. Pivots are only taken from the first m columns.
+// Retuns a permutation matrix that can be applied to a solution to regain
+// initial order.
+func GaussFullPivot[T Scalar](a *M[T]) *M[T] {
+	if a.cols <= a.rows {
+		panic(mat.Errorf(mat.ErrShape, "matrix must have shape m/m+k"))
+	}
+	perm := Eye[T](a.rows)
+	tol := tolerance(a, a.rows, a.rows)
+	for k := 1; k <= a.rows; k++ {
+		// look for pivots in the remaining coefficients
+		rmax, cmax := a.MaxIndex(k, k, a.rows, a.rows)
+		if abs(a.Get(rmax, cmax)) <= tol {
+			// no pivot at all, we're done, rest is zeroes
+			return perm
+		}
+		a.SwapRows(rmax, k)
+		a.SwapCols(cmax, k)
+		perm.SwapCols(cmax, k)
+		eliminate(a, k, k)
+	}
+	return perm
+}
+
+// eliminate transforms all elements below the pivot at row, col to
+// zeroes. The pivot must not be zero.
+func eliminate[T Scalar](a *M[T], row, col int) {
+	pivot := a.Get(row, col)
+	for i := row + 1; i <= a.rows; i++ {
+		f := a.Get(i, col) / pivot
+		a.Set(i, col, 0)
+		for j := col + 1; j <= a.cols; j++ {
+			a.Set(i, j, a.Get(i, j)-a.Get(row, j)*f)
+		}
+	}
+}
+
+// SolveGaussSimple solves the linear equation system  AX = B
+// by gaussian elimination with no pivoting. Panics if A
+// is not a square matrix or B does not have as many rows as A.
+// B may have any number of columns, all of them are solved with a
+// single elimination.
+// Returns error if system does not have a single solution or
+// gaussian elimination cannot proceed (zeroes on diagonal).
+func SolveGaussSimple[T Scalar](A, B *M[T]) (*M[T], error) {
+	a := augmentSystem(A, B)
+	if err := GaussSimple(a); err != nil {
+		return nil, err
+	}
+	return SolveUpper(a)
+}
+
+// SolveGaussPartial solves the linear equation system  AX = B
+// by gaussian elimination with partial pivoting. Panics if A
+// is not a square matrix or B does not have as many rows as A.
+// B may have any number of columns, all of them are solved with a
+// single elimination.
+// Returns error if system does not have a single solution.
+func SolveGaussPartial[T Scalar](A, B *M[T]) (*M[T], error) {
+	a := augmentSystem(A, B)
+	gaussPartialPivot(a, A.cols, tolerance(A, A.rows, A.cols))
+	return SolveUpper(a)
+}
+
+// SolveGaussFull solves the linear equation system  AX = B
+// by gaussian elimination with full pivoting. Panics if A
+// is not a square matrix or B does not have as many rows as A.
+// B may have any number of columns, all of them are solved with a
+// single elimination.
+// Returns error if system does not have a single solution.
+func SolveGaussFull[T Scalar](A, B *M[T]) (*M[T], error) {
+	a := augmentSystem(A, B)
+	perm := GaussFullPivot(a)
+	x, err := SolveUpper(a)
+	if err != nil {
+		return nil, err
+	}
+	return perm.Mul(x), nil
+}
+
+// tolerance is the modulus below which elements of the top left rows x
+// cols block of a are treated as zeroes: the largest element scaled by
+// the size of the block and the precision of T.
+func tolerance[T Scalar](a *M[T], rows, cols int) float64 {
+	r, c := a.MaxIndex(1, 1, rows, cols)
+	if cols > rows {
+		rows = cols
+	}
+	return float64(rows) * abs(a.Get(r, c)) * eps[T]()
+}
+
+// augmentSystem checks the shapes of AX = B and returns [A | B].
+func augmentSystem[T Scalar](A, B *M[T]) *M[T] {
+	if A.rows != A.cols {
+		panic(mat.Errorf(mat.ErrShape, "gaussian solver only works on square matrices"))
+	}
+	if B.rows != A.rows {
+		panic(mat.Errorf(mat.ErrShape, "free terms must have as many rows as A"))
+	}
+	return A.Augment(B)
+}
@@ -0,0 +1,93 @@
+package generic
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/rciurlea/cn/mat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveGaussComplex(t *testing.T) {
+	// AC circuit: a 10V source with 10 ohm internal resistance feeds node
+	// 1, a 1mH inductor joins nodes 1 and 2, and node 2 goes to ground
+	// through 20 ohm in parallel with 100uF. Nodal analysis at 50Hz,
+	// YV = I with admittances on the diagonals.
+	w := 2 * math.Pi * 50
+	yl := 1 / complex(0, w*1e-3)
+	yc := complex(0, w*100e-6)
+	Y := New(2, 2,
+		0.1+yl, -yl,
+		-yl, yl+0.05+yc)
+	I := Vec[complex128](1, 0)
+	solvers := []func(A, B *M[complex128]) (*M[complex128], error){
+		SolveGaussSimple[complex128],
+		SolveGaussPartial[complex128],
+		SolveGaussFull[complex128],
+	}
+	for i, solve := range solvers {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			V, err := solve(Y, I)
+			assert.NoError(t, err)
+			assertInDelta(t, I, Y.Mul(V), 1e-12)
+			// the node 2 voltage lags behind the source
+			assert.True(t, cmplx.Phase(V.Get(2, 1)) < 0)
+		})
+	}
+}
+
+func TestSolveGaussPivoting(t *testing.T) {
+	A := New[complex128](3, 3, 0, 1i, 2, 1, 0, -1i, 2i, 1, 0)
+	B := New[complex128](3, 2, 1, 1i, 2, 0, 3i, 1)
+	_, err := SolveGaussSimple(A, B)
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	for _, solve := range []func(A, B *M[complex128]) (*M[complex128], error){
+		SolveGaussPartial[complex128],
+		SolveGaussFull[complex128],
+	} {
+		X, err := solve(A, B)
+		assert.NoError(t, err)
+		assertInDelta(t, B, A.Mul(X), 1e-12)
+	}
+
+	S := New[complex128](2, 2, 1, 1i, 1i, -1)
+	_, err = SolveGaussPartial(S, Vec[complex128](1, 1))
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	_, err = SolveGaussFull(S, Vec[complex128](1, 1))
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	assert.Panics(t, func() { SolveGaussPartial(S, Vec[complex128](1)) })
+
+	// singular only up to rounding
+	R := New[complex128](3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	b := Vec[complex128](1, 2, 4)
+	_, err = SolveGaussSimple(R, b)
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	_, err = SolveGaussPartial(R, b)
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	_, err = SolveGaussFull(R, b)
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+}
+
+func TestSolveGaussFloat32(t *testing.T) {
+	A := New[float32](3, 3, 2, 1, -1, -3, -1, 2, -2, 1, 2)
+	x, err := SolveGaussPartial(A, Vec[float32](8, -11, -3))
+	assert.NoError(t, err)
+	assertInDelta(t, Vec[float32](2, 3, -1), x, 1e-5)
+	x, err = SolveGaussFull(A, Vec[float32](8, -11, -3))
+	assert.NoError(t, err)
+	assertInDelta(t, Vec[float32](2, 3, -1), x, 1e-5)
+}
+
+func TestSolveUpper(t *testing.T) {
+	a := New[complex64](2, 3, 2i, 1, 1, 0, 1, 1i)
+	x, err := SolveUpper(a)
+	assert.NoError(t, err)
+	assertInDelta(t, Vec[complex64](-0.5-0.5i, 1i), x, 1e-6)
+	_, err = SolveUpper(New[complex64](2, 3, 1, 1, 1, 0, 0, 1))
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	assert.Panics(t, func() { SolveUpper(Eye[complex64](2)) })
+	assert.Panics(t, func() { GaussFullPivot(Eye[complex64](2)) })
+}
@@ -0,0 +1,258 @@
+// Package generic provides a dense matrix type parameterised over its
+// element type, so the same algorithms work on float32 data for memory
+// bound workloads and on complex numbers, e.g. for AC circuit analysis.
+// It mirrors the API of package mat: indices are 1 based, storage is by
+// columns, invalid input panics with a *mat.Error and failures return
+// errors of the same kinds.
+package generic
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strings"
+
+	"github.com/rciurlea/cn/mat"
+)
+
+// Scalar is the set of supported element types.
+type Scalar interface {
+	float32 | float64 | complex64 | complex128
+}
+
+// M is a matrix with elements of type T.
+type M[T Scalar] struct {
+	rows int
+	cols int
+	data []T
+}
+
+// New initializes a matrix of specified size. If initial values
+// are provided, these will be used to fill the matrix line by
+// line. Only up to rows * cols values will be taken into account.
+// Panics if asked to create a 0 size matrix.
+func New[T Scalar](rows, cols int, xs ...T) *M[T] {
+	if rows <= 0 || cols <= 0 {
+		panic(mat.Errorf(mat.ErrShape, "invalid matrix size (%d x %d)", rows, cols))
+	}
+	m := &M[T]{rows: rows, cols: cols, data: make([]T, rows*cols)}
+	for k := 0; k < len(xs) && k < rows*cols; k++ {
+		m.Set(k/cols+1, k%cols+1, xs[k])
+	}
+	return m
+}
+
+// Eye builds the identity matrix.
+func Eye[T Scalar](n int) *M[T] {
+	m := New[T](n, n)
+	for i := 1; i <= n; i++ {
+		m.Set(i, i, 1)
+	}
+	return m
+}
+
+// Vec creates a column vector (n x 1 matrix) given xs.
+func Vec[T Scalar](xs ...T) *M[T] {
+	if len(xs) == 0 {
+		panic(mat.Errorf(mat.ErrShape, "can't create empty vector"))
+	}
+	return New(len(xs), 1, xs...)
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (m *M[T]) Dims() (int, int) {
+	return m.rows, m.cols
+}
+
+// Set the matrix element at row/col to a new value. Panics if indices
+// exceed matrix size.
+func (m *M[T]) Set(row, col int, value T) {
+	if row > m.rows || row <= 0 || col > m.cols || col <= 0 {
+		panic(mat.Errorf(mat.ErrIndex, "invalid matrix indices: %d, %d", row, col))
+	}
+	m.data[m.rows*(col-1)+row-1] = value
+}
+
+// Get value at row/col. Panics if indices exceed matrix size.
+func (m *M[T]) Get(row, col int) T {
+	if row > m.rows || row <= 0 || col > m.cols || col <= 0 {
+		panic(mat.Errorf(mat.ErrIndex, "invalid matrix indices: %d, %d", row, col))
+	}
+	return m.data[m.rows*(col-1)+row-1]
+}
+
+// Equals compares matrices for equality, within a relative margin suited
+// to the precision of T. Panics if sizes differ.
+func (m *M[T]) Equals(other *M[T]) bool {
+	if m.rows != other.rows || m.cols != other.cols {
+		panic(mat.Errorf(mat.ErrShape, "trying to compare matrices of different sizes: %dx%d, %dx%d", m.rows, m.cols, other.rows, other.cols))
+	}
+	tol := math.Sqrt(eps[T]())
+	for i, a := range m.data {
+		b := other.data[i]
+		if a != b && abs(a-b) >= tol*abs(a) {
+			return false
+		}
+	}
+	return true
+}
+
+// Augment matrix with another matrix, returning a new matrix.
+// The two matrices must have the same number or rows.
+func (m *M[T]) Augment(other *M[T]) *M[T] {
+	if m.rows != other.rows {
+		panic(mat.Errorf(mat.ErrShape, "can't augment matrices of different heights"))
+	}
+	aug := New[T](m.rows, m.cols+other.cols)
+	copy(aug.data, m.data)
+	copy(aug.data[len(m.data):], other.data)
+	return aug
+}
+
+// Slice the matrix, returning a copy of the submatrix between the given
+// coordinates. Panics if invalid indices are provided.
+func (m *M[T]) Slice(r1, c1, r2, c2 int) *M[T] {
+	if r1 < 1 || c1 < 1 || r2 > m.rows || c2 > m.cols || r2 < r1 || c2 < c1 {
+		panic(mat.Errorf(mat.ErrIndex, "invalid indices: %d %d %d %d", r1, c1, r2, c2))
+	}
+	s := New[T](r2-r1+1, c2-c1+1)
+	for j := c1; j <= c2; j++ {
+		copy(s.data[(j-c1)*s.rows:], m.data[(j-1)*m.rows+r1-1:(j-1)*m.rows+r2])
+	}
+	return s
+}
+
+// Mul multiplies two matrices. If the first has a rows and b columns
+// the second must have b rows and c colums. Result has a rows and c cols.
+func (m *M[T]) Mul(other *M[T]) *M[T] {
+	if m.cols != other.rows {
+		panic(mat.Errorf(mat.ErrShape, "can't multiply matrices of shapes %dx%d and %dx%d", m.rows, m.cols, other.rows, other.cols))
+	}
+	res := New[T](m.rows, other.cols)
+	for j := 0; j < other.cols; j++ {
+		c := res.data[j*res.rows : (j+1)*res.rows]
+		for k := 0; k < m.cols; k++ {
+			b := other.data[j*other.rows+k]
+			for i, a := range m.data[k*m.rows : (k+1)*m.rows] {
+				c[i] += a * b
+			}
+		}
+	}
+	return res
+}
+
+// SwapRows in place. Panics if row numbers are invalid.
+func (m *M[T]) SwapRows(i, j int) {
+	if i <= 0 || i > m.rows || j <= 0 || j > m.rows {
+		panic(mat.Errorf(mat.ErrIndex, "invalid row numbers: %d %d", i, j))
+	}
+	for c := 0; c < m.cols; c++ {
+		m.data[c*m.rows+i-1], m.data[c*m.rows+j-1] = m.data[c*m.rows+j-1], m.data[c*m.rows+i-1]
+	}
+}
+
+// SwapCols in place. Panics if column numbers are invalid.
+func (m *M[T]) SwapCols(i, j int) {
+	if i <= 0 || i > m.cols || j <= 0 || j > m.cols {
+		panic(mat.Errorf(mat.ErrIndex, "invalid column numbers: %d %d", i, j))
+	}
+	for r := 0; r < m.rows; r++ {
+		m.data[(i-1)*m.rows+r], m.data[(j-1)*m.rows+r] = m.data[(j-1)*m.rows+r], m.data[(i-1)*m.rows+r]
+	}
+}
+
+// MaxIndex returns the row and column of the largest element
+// (absolute value) in the matrix within the specified bounds.
+func (m *M[T]) MaxIndex(r1, c1, r2, c2 int) (int, int) {
+	max := -1.0
+	r, c := r1, c1
+	for j := c1; j <= c2; j++ {
+		for i := r1; i <= r2; i++ {
+			if v := abs(m.Get(i, j)); v > max {
+				max = v
+				r, c = i, j
+			}
+		}
+	}
+	return r, c
+}
+
+// Transpose matrix returning a new matrix. Complex elements are not
+// conjugated, see ConjTranspose.
+func (m *M[T]) Transpose() *M[T] {
+	t := New[T](m.cols, m.rows)
+	for i := 1; i <= m.rows; i++ {
+		for j := 1; j <= m.cols; j++ {
+			t.Set(j, i, m.Get(i, j))
+		}
+	}
+	return t
+}
+
+// ConjTranspose returns the conjugate transpose of m as a new matrix. For
+// real element types it is the same as Transpose.
+func (m *M[T]) ConjTranspose() *M[T] {
+	t := m.Transpose()
+	for i, v := range t.data {
+		t.data[i] = conj(v)
+	}
+	return t
+}
+
+// Clone a matrix, returning an identical matrix which shares no memory.
+func (m *M[T]) Clone() *M[T] {
+	n := New[T](m.rows, m.cols)
+	copy(n.data, m.data)
+	return n
+}
+
+// String makes matrices printable
+func (m *M[T]) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintln(b)
+	for i := 1; i <= m.rows; i++ {
+		for j := 1; j <= m.cols; j++ {
+			fmt.Fprintf(b, "%.4g\t", m.Get(i, j))
+		}
+		fmt.Fprintln(b)
+	}
+	fmt.Fprintln(b)
+	return b.String()
+}
+
+// abs returns the absolute value, or modulus, of x.
+func abs[T Scalar](x T) float64 {
+	switch v := any(x).(type) {
+	case float32:
+		return math.Abs(float64(v))
+	case float64:
+		return math.Abs(v)
+	case complex64:
+		return cmplx.Abs(complex128(v))
+	case complex128:
+		return cmplx.Abs(v)
+	}
+	panic("unreachable")
+}
+
+// conj returns the complex conjugate of x, or x itself if it is real.
+func conj[T Scalar](x T) T {
+	switch v := any(x).(type) {
+	case complex64:
+		return any(complex(real(v), -imag(v))).(T)
+	case complex128:
+		return any(cmplx.Conj(v)).(T)
+	}
+	return x
+}
+
+// eps returns the distance between 1 and the next larger number of the
+// precision of T.
+func eps[T Scalar]() float64 {
+	var x T
+	switch any(x).(type) {
+	case float32, complex64:
+		return 1.0 / (1 << 23)
+	}
+	return 1.0 / (1 << 52)
+}
@@ -0,0 +1,76 @@
+package generic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rciurlea/cn/mat"
+	"github.com/stretchr/testify/assert"
+)
+
+// assertInDelta checks that all elements of actual are within delta of
+// those in expected, measured by modulus.
+func assertInDelta[T Scalar](t *testing.T, expected, actual *M[T], delta float64) {
+	t.Helper()
+	if !assert.Equal(t, expected.rows, actual.rows) || !assert.Equal(t, expected.cols, actual.cols) {
+		return
+	}
+	for i, v := range expected.data {
+		if abs(v-actual.data[i]) > delta {
+			t.Errorf("element %d: expected %v, got %v%s", i, v, actual.data[i], actual)
+			return
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	m := New[complex128](2, 2, 1, 2i, 3, 4+1i)
+	assert.Equal(t, 2i, m.Get(1, 2))
+	assert.Equal(t, complex128(3), m.Get(2, 1))
+	assert.Equal(t, []float32{1, 0, 0, 1}, Eye[float32](2).data)
+	r, c := Vec[float64](1, 2, 3).Dims()
+	assert.Equal(t, 3, r)
+	assert.Equal(t, 1, c)
+
+	err := func() (err error) {
+		defer func() { err = recover().(error) }()
+		New[float64](0, 1)
+		return nil
+	}()
+	assert.True(t, errors.Is(err, mat.ErrShape))
+	assert.Panics(t, func() { m.Get(3, 1) })
+	assert.Panics(t, func() { Vec[float32]() })
+}
+
+func TestEquals(t *testing.T) {
+	a := New[float32](2, 2, 1, 2, 3, 4)
+	assert.True(t, a.Equals(New[float32](2, 2, 1, 2, 3, 4.0000001)))
+	assert.False(t, a.Equals(New[float32](2, 2, 1, 2, 3, 4.01)))
+	z := New[complex128](1, 2, 1i, 2)
+	assert.True(t, z.Equals(z.Clone()))
+	assert.False(t, z.Equals(New[complex128](1, 2, -1i, 2)))
+	assert.Panics(t, func() { a.Equals(Eye[float32](3)) })
+}
+
+func TestMul(t *testing.T) {
+	a := New[complex128](2, 2, 1, 1i, -1i, 2)
+	b := Vec[complex128](1i, 1)
+	assert.Equal(t, Vec[complex128](2i, 3).data, a.Mul(b).data)
+	f := New[float32](2, 3, 1, 2, 3, 4, 5, 6)
+	assert.Equal(t, New[float32](2, 2, 14, 32, 32, 77).data, f.Mul(f.Transpose()).data)
+	assert.Panics(t, func() { f.Mul(f) })
+}
+
+func TestManipulation(t *testing.T) {
+	a := New[complex128](2, 3, 1, 2i, 3, 4, 5, 6-1i)
+	assert.Equal(t, New[complex128](3, 2, 1, 4, -2i, 5, 3, 6+1i).data, a.ConjTranspose().data)
+	assert.Equal(t, New[complex128](2, 1, 2i, 5).data, a.Slice(1, 2, 2, 2).data)
+	assert.Equal(t, New[complex128](2, 4, 1, 2i, 3, 1, 4, 5, 6-1i, 0).data, a.Augment(Vec[complex128](1, 0)).data)
+	r, c := a.MaxIndex(1, 1, 2, 3)
+	assert.Equal(t, []int{2, 3}, []int{r, c})
+	a.SwapRows(1, 2)
+	a.SwapCols(1, 3)
+	assert.Equal(t, New[complex128](2, 3, 6-1i, 5, 4, 3, 2i, 1).data, a.data)
+	assert.Panics(t, func() { a.Slice(1, 1, 3, 1) })
+	assert.Panics(t, func() { a.SwapRows(1, 3) })
+}
@@ -0,0 +1,43 @@
+package generic
+
+import "github.com/rciurlea/cn/mat"
+
+// GaussJordan atempts to invert a square matrix A using
+// the Gauss-Jordan method with partial pivoting. Pivots no larger
+// than a tolerance based on the size, largest element and precision
+// of A are treated as zeroes. Panics if A is not square and returns a
+// *mat.SingularError if it's not invertible.
+func GaussJordan[T Scalar](A *M[T]) (*M[T], error) {
+	if A.rows != A.cols {
+		panic(mat.Errorf(mat.ErrShape, "matrix not square"))
+	}
+	n := A.rows
+	orig := A
+	tol := tolerance(A, n, n)
+	A = A.Augment(Eye[T](n))
+	// create zeroes below diagonal, set diagonal to 1
+	for i := 1; i <= n; i++ {
+		r, _ := A.MaxIndex(i, i, n, i)
+		if abs(A.Get(r, i)) <= tol {
+			return nil, &mat.SingularError{Col: i, Rank: gaussPartialPivot(orig.Clone(), n, tol)}
+		}
+		A.SwapRows(r, i)
+		f := 1 / A.Get(i, i)
+		A.Set(i, i, 1)
+		for j := i + 1; j <= A.cols; j++ {
+			A.Set(i, j, A.Get(i, j)*f)
+		}
+		eliminate(A, i, i)
+	}
+	// create zeroes above diagonal
+	for i := n; i >= 1; i-- {
+		for j := i - 1; j >= 1; j-- {
+			f := A.Get(j, i)
+			A.Set(j, i, 0)
+			for k := i + 1; k <= A.cols; k++ {
+				A.Set(j, k, A.Get(j, k)-f*A.Get(i, k))
+			}
+		}
+	}
+	return A.Slice(1, n+1, n, 2*n), nil
+}
@@ -0,0 +1,40 @@
+package generic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rciurlea/cn/mat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGaussJordan(t *testing.T) {
+	A := New[complex128](3, 3, 0, 1i, 2, 1, 0, -1i, 2i, 1, 0)
+	inv, err := GaussJordan(A)
+	assert.NoError(t, err)
+	assertInDelta(t, Eye[complex128](3), A.Mul(inv), 1e-12)
+	assertInDelta(t, Eye[complex128](3), inv.Mul(A), 1e-12)
+
+	// a permutation matrix needs row swaps
+	P := New[float32](3, 3, 0, 1, 0, 0, 0, 1, 1, 0, 0)
+	inv32, err := GaussJordan(P)
+	assert.NoError(t, err)
+	assert.True(t, P.Transpose().Equals(inv32))
+
+	_, err = GaussJordan(New[complex128](2, 2, 1, 1i, 1i, -1))
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	var serr *mat.SingularError
+	if assert.True(t, errors.As(err, &serr)) {
+		assert.Equal(t, 2, serr.Col)
+		assert.Equal(t, 1, serr.Rank)
+	}
+	_, err = GaussJordan(New[complex128](3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9))
+	if assert.True(t, errors.As(err, &serr)) {
+		assert.Equal(t, 3, serr.Col)
+		assert.Equal(t, 2, serr.Rank)
+	}
+	// singular only up to float32 rounding
+	_, err = GaussJordan(New[float32](2, 2, 1, 1.0/3, 3, 1))
+	assert.True(t, errors.Is(err, mat.ErrSingular))
+	assert.Panics(t, func() { GaussJordan(New[complex128](2, 3)) })
+}
@@ -1,21 +1,82 @@
 package mat
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
+
+// SingularError is returned when a matrix cannot be inverted because no
+// usable pivot is left in a column.
+type SingularError struct {
+	// Col is the column of A where elimination failed.
+	Col int
+	// Rank is an estimate of the rank of A.
+	Rank int
+}
+
+func (e *SingularError) Error() string {
+	return fmt.Sprintf("matrix not invertible, no pivot in column %d, rank is about %d", e.Col, e.Rank)
+}
+
+// Unwrap returns ErrSingular.
+func (e *SingularError) Unwrap() error {
+	return ErrSingular
+}
 
 // GaussJordan atempts to invert a square matrix A using
-// the Gauss-Jordan method. Panics if A is not square and
-// returns an error if it's not invertible.
-func GaussJordan(A *M) (*M, error) {
+// the Gauss-Jordan method with partial pivoting. Panics if A
+// is not square and returns a *SingularError if it's not
+// invertible. See WithCondLimit for detecting ill-conditioned
+// matrices.
+func GaussJordan(A *M, opts ...SolveOption) (*M, error) {
+	return gaussJordan(A, false, opts)
+}
+
+// GaussJordanFull atempts to invert a square matrix A using
+// the Gauss-Jordan method with full pivoting. It is slower
+// than GaussJordan but more stable, and the rank it reports
+// on failure is exact up to rounding. Panics if A is not square
+// and returns a *SingularError if it's not invertible. See
+// WithCondLimit for detecting ill-conditioned matrices.
+func GaussJordanFull(A *M, opts ...SolveOption) (*M, error) {
+	return gaussJordan(A, true, opts)
+}
+
+func gaussJordan(A *M, full bool, opts []SolveOption) (*M, error) {
 	if A.rows != A.cols {
-		panic("matrix not square")
+		panic(errorf(ErrShape, "matrix not square"))
+	}
+	orig := A
+	n := A.rows
+	tol := rankTolerance(A)
+	A = A.Augment(Eye(n))
+	// unknowns get reordered by column swaps
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i + 1
 	}
-	A = A.Augment(Eye(A.rows))
 	// create zeroes below diagonal, set diagonal to 1
-	for i := 1; i <= A.rows; i++ {
-		pivot := A.Get(i, i)
-		if almostEqual(pivot, 0) {
-			return nil, fmt.Errorf("matrix not invertible")
+	for i := 1; i <= n; i++ {
+		lastCol := i
+		if full {
+			lastCol = n
+		}
+		r, c := A.MaxIndex(i, i, n, lastCol)
+		if math.Abs(A.Get(r, c)) <= tol {
+			// with full pivoting everything left is zero, so the
+			// pivots found so far give the rank
+			rank := i - 1
+			if !full {
+				rank = Rank(orig, tol)
+			}
+			return nil, &SingularError{Col: perm[i-1], Rank: rank}
+		}
+		A.SwapRows(r, i)
+		if c != i {
+			A.SwapCols(c, i)
+			perm[c-1], perm[i-1] = perm[i-1], perm[c-1]
 		}
+		pivot := A.Get(i, i)
 		// set pivot to 1
 		f := 1 / pivot
 		A.Set(i, i, 1)
@@ -42,5 +103,10 @@ func GaussJordan(A *M) (*M, error) {
 			}
 		}
 	}
-	return A.Slice(1, A.cols/2+1, A.rows, A.cols), nil
+	// row i of the result belongs to unknown perm[i]
+	inv := New(n, n)
+	for i := 1; i <= n; i++ {
+		A.View(i, n+1, i, 2*n).CopyTo(inv.Row(perm[i-1]))
+	}
+	return inv, checkCond(orig, opts)
 }
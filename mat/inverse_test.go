@@ -11,8 +11,8 @@ func TestGaussJordan(t *testing.T) {
 	n, err := GaussJordan(m)
 	assert.NoError(t, err)
 	assert.True(t, n.Equals(New(3, 3, -40, 16, 9, 13, -5, -3, 5, -2, -1)))
-	assert.True(t, m.Mul(n).Equals(Eye(3)))
-	assert.True(t, n.Mul(m).Equals(Eye(3)))
+	assertInDelta(t, Eye(3), m.Mul(n), 1e-12)
+	assertInDelta(t, Eye(3), n.Mul(m), 1e-12)
 }
 
 func TestGaussJordanError(t *testing.T) {
@@ -20,3 +20,48 @@ func TestGaussJordanError(t *testing.T) {
 	m, err := GaussJordan(m)
 	assert.Error(t, err)
 }
+
+func TestGaussJordanPivoting(t *testing.T) {
+	tcs := []*M{
+		New(2, 2, 0, 1, 1, 0),
+		New(3, 3, 0, 0, 1, 1, 0, 0, 0, 1, 0),
+		New(3, 3, 0, 2, 1, 1, 0, 3, 4, -1, 0),
+		New(3, 3, 1e-20, 1, 1, 1, 2, 3, 1, 1, 5),
+	}
+	for i, m := range tcs {
+		for _, invert := range []func(*M, ...SolveOption) (*M, error){GaussJordan, GaussJordanFull} {
+			n, err := invert(m)
+			assert.NoError(t, err, "#%d", i)
+			assertInDelta(t, Eye(m.rows), m.Mul(n), 1e-12)
+			assertInDelta(t, Eye(m.rows), n.Mul(m), 1e-12)
+		}
+	}
+}
+
+func TestGaussJordanSingularError(t *testing.T) {
+	tcs := []struct {
+		name   string
+		m      *M
+		invert func(*M, ...SolveOption) (*M, error)
+		col    int
+		rank   int
+	}{
+		{"partial", New(3, 3, 1, 2, 3, 4, 5, 6, 2, 4, 6), GaussJordan, 3, 2},
+		{"partial zero column", New(3, 3, 1, 0, 3, 4, 0, 6, 2, 0, 7), GaussJordan, 2, 2},
+		{"partial rank one", New(3, 3, 1, 2, 3, 2, 4, 6, 3, 6, 9), GaussJordan, 2, 1},
+		{"full", New(3, 3, 1, 2, 3, 4, 5, 6, 2, 4, 6), GaussJordanFull, 2, 2},
+		{"full zero column", New(3, 3, 1, 0, 3, 4, 0, 6, 2, 0, 7), GaussJordanFull, 2, 2},
+		{"full rank one", New(3, 3, 1, 2, 3, 2, 4, 6, 3, 6, 9), GaussJordanFull, 2, 1},
+		{"full zero", New(2, 2), GaussJordanFull, 1, 0},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.invert(tc.m)
+			serr, ok := err.(*SingularError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tc.col, serr.Col)
+				assert.Equal(t, tc.rank, serr.Rank)
+			}
+		})
+	}
+}
@@ -5,35 +5,238 @@ import (
 	"math"
 )
 
-// SolveJacobi solves Ax = b using the Jacobi iterative method. Returns
-// computed x or an error if the method does not converge. Panics if
-// arguments don't have the correct shape.
-func SolveJacobi(A, b, x0 *M, epsilon float64, maxIterations uint) (*M, error) {
-	// validate input size
-	if A.rows != A.cols {
-		panic("square matrix must be provided")
+// StopReason tells why an iterative solver stopped.
+type StopReason int
+
+// Reasons for an iterative solver to stop.
+const (
+	// Running means the solver has not stopped yet. It is the zero value,
+	// so no result reports success unless a solver decided it.
+	Running StopReason = iota
+	// Converged means the residual dropped below epsilon.
+	Converged
+	// Diverged means the residual grew past the divergence limit or
+	// stopped being a finite number.
+	Diverged
+	// Stalled means the residual did not improve for too many iterations.
+	Stalled
+	// IterationLimit means maxIterations was reached first.
+	IterationLimit
+	// Stopped means the iteration callback asked to stop.
+	Stopped
+	// BrokeDown means the method hit a division by zero, e.g. CG on a
+	// matrix which is not positive definite.
+	BrokeDown
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case Running:
+		return "running"
+	case Converged:
+		return "converged"
+	case Diverged:
+		return "diverged"
+	case Stalled:
+		return "stalled"
+	case IterationLimit:
+		return "iteration limit exceeded"
+	case Stopped:
+		return "stopped by callback"
+	case BrokeDown:
+		return "broke down"
 	}
-	if b.cols != 1 || b.rows != A.rows {
-		panic(fmt.Sprintf("b vector has the wrong shape: %d x %d", b.rows, b.cols))
+	return fmt.Sprintf("StopReason(%d)", int(r))
+}
+
+// IterResult describes how an iterative solver run went.
+type IterResult struct {
+	// Iterations is the number of iterations performed.
+	Iterations uint
+	// Residuals holds the residual after each iteration. For Jacobi and
+	// SOR this is the norm of the change in x, which is what they compare
+	// against epsilon; for the Krylov solvers it is ||b - Ax||.
+	Residuals []float64
+	// Reason tells why the solver stopped.
+	Reason StopReason
+}
+
+// Residual returns the last recorded residual, 0 if no iteration ran.
+func (r *IterResult) Residual() float64 {
+	if len(r.Residuals) == 0 {
+		return 0
 	}
-	if x0.cols != 1 || x0.rows != A.rows {
-		panic(fmt.Sprintf("x0 vector has the wrong shape: %d x %d", x0.rows, x0.cols))
+	return r.Residuals[len(r.Residuals)-1]
+}
+
+// IterCallback is called by the iterative solvers after every iteration
+// with the iteration number (1 based) and residual. Returning false stops
+// the solver.
+type IterCallback func(iteration uint, residual float64) bool
+
+// IterOption configures optional behaviour of the iterative solvers.
+type IterOption func(*iterSettings)
+
+type iterSettings struct {
+	precond    Preconditioner
+	callback   IterCallback
+	divergence float64
+	stall      uint
+}
+
+func newIterSettings(opts []IterOption) *iterSettings {
+	s := &iterSettings{precond: identity{}}
+	for _, opt := range opts {
+		opt(s)
 	}
-	if epsilon < 0 {
-		panic("negative error margin")
+	return s
+}
+
+// WithPreconditioner makes a Krylov solver work on the system
+// preconditioned by P, which should approximate A and be cheap to solve.
+// Jacobi and SOR ignore it.
+func WithPreconditioner(P Preconditioner) IterOption {
+	return func(s *iterSettings) {
+		if P != nil {
+			s.precond = P
+		}
+	}
+}
+
+// WithCallback calls fn after every iteration, e.g. to log progress or
+// to stop early.
+func WithCallback(fn IterCallback) IterOption {
+	return func(s *iterSettings) {
+		s.callback = fn
+	}
+}
+
+// WithDivergenceLimit stops a solver once the residual grows to factor
+// times the first one. The check is off by default, 0 disables it.
+func WithDivergenceLimit(factor float64) IterOption {
+	return func(s *iterSettings) {
+		s.divergence = factor
+	}
+}
+
+// WithStallLimit stops a solver once the smallest residual seen has not
+// improved for n iterations. The check is off by default, 0 disables it.
+func WithStallLimit(n uint) IterOption {
+	return func(s *iterSettings) {
+		s.stall = n
+	}
+}
+
+// monitor records residuals of an iterative solver run and decides when
+// it should stop.
+type monitor struct {
+	settings      *iterSettings
+	epsilon       float64
+	maxIterations uint
+	res           *IterResult
+	ref, best     float64
+	bestAt        uint
+}
+
+func newMonitor(settings *iterSettings, epsilon float64, maxIterations uint) *monitor {
+	return &monitor{
+		settings:      settings,
+		epsilon:       epsilon,
+		maxIterations: maxIterations,
+		res:           &IterResult{},
+		best:          math.Inf(1),
+	}
+}
+
+// start checks the residual of the initial guess, returning true if no
+// iteration is needed or allowed. It also serves as reference for
+// divergence detection.
+func (m *monitor) start(res float64) bool {
+	m.ref = res
+	if res < m.epsilon {
+		m.res.Reason = Converged
+		return true
 	}
+	if m.maxIterations == 0 {
+		m.res.Reason = IterationLimit
+		return true
+	}
+	return false
+}
+
+// step records the residual after an iteration, returning true if the
+// solver should stop; the reason is then available in the result.
+func (m *monitor) step(res float64) bool {
+	r := m.res
+	r.Iterations++
+	r.Residuals = append(r.Residuals, res)
+	if m.ref == 0 {
+		m.ref = res
+	}
+	stop := m.settings.callback != nil && !m.settings.callback(r.Iterations, res)
+	if res < m.epsilon {
+		r.Reason = Converged
+		return true
+	}
+	if math.IsNaN(res) || math.IsInf(res, 0) || (m.settings.divergence > 0 && res > m.settings.divergence*m.ref) {
+		r.Reason = Diverged
+		return true
+	}
+	if res < m.best {
+		m.best, m.bestAt = res, r.Iterations
+	} else if m.settings.stall > 0 && r.Iterations-m.bestAt >= m.settings.stall {
+		r.Reason = Stalled
+		return true
+	}
+	if stop {
+		r.Reason = Stopped
+		return true
+	}
+	if r.Iterations >= m.maxIterations {
+		r.Reason = IterationLimit
+		return true
+	}
+	return false
+}
+
+// fail marks the run as broken down, returning err for convenience.
+func (m *monitor) fail(err error) error {
+	m.res.Reason = BrokeDown
+	return err
+}
+
+// err returns the error matching the stop reason, nil if converged.
+func (m *monitor) err() error {
+	if m.res.Reason == Converged {
+		return nil
+	}
+	return errorf(ErrNotConverged, "%s", m.res.Reason)
+}
+
+// SolveJacobi solves Ax = b using the Jacobi iterative method. A can be
+// dense or sparse. Stops once the change in x between iterations drops
+// below epsilon. Returns computed x along with convergence details, and
+// an error if the method does not converge, in which case x holds the
+// last iterate. Panics if arguments don't have the correct shape.
+func SolveJacobi(A Matrix, b, x0 *M, epsilon float64, maxIterations uint, opts ...IterOption) (*M, *IterResult, error) {
+	checkIterativeArgs(A, b, x0, epsilon)
+	mon := newMonitor(newIterSettings(opts), epsilon, maxIterations)
 	xp := x0.Clone() // previous x
 	x := xp.Clone()  // start with x same as x0
+	if maxIterations == 0 {
+		mon.res.Reason = IterationLimit
+		return x, mon.res, mon.err()
+	}
 	var s float64
-	for k := 0; k < int(maxIterations); k++ {
+	for {
 		// calculate new x term by term
 		for i := 1; i <= x.rows; i++ {
 			s = 0
-			for j := 1; j <= x.rows; j++ {
+			A.DoRowNonZero(i, func(j int, v float64) {
 				if j != i {
-					s += A.Get(i, j) * xp.Get(j, 1)
+					s += v * xp.Get(j, 1)
 				}
-			}
+			})
 			x.Set(i, 1, (b.Get(i, 1)-s)/A.Get(i, i))
 		}
 		// calculate error and stop if appropriate
@@ -41,55 +244,410 @@ func SolveJacobi(A, b, x0 *M, epsilon float64, maxIterations uint) (*M, error) {
 		for i := 1; i <= x.rows; i++ {
 			s += math.Pow((x.Get(i, 1) - xp.Get(i, 1)), 2)
 		}
-		if math.Sqrt(s) < epsilon {
-			return x, nil
+		if mon.step(math.Sqrt(s)) {
+			return x, mon.res, mon.err()
 		}
 		x.CopyTo(xp)
 	}
-	return nil, fmt.Errorf("iteration limit exceeded")
 }
 
 // SolveGaussSeidel solves Ax = b using the Gauss-Seidel iterative method.
-// Returns computed x or an error if the method does not converge. Panics
-// if arguments don't have the correct shape.
-func SolveGaussSeidel(A, b, x0 *M, epsilon float64, maxIterations uint) (*M, error) {
-	// validate input size
-	if A.rows != A.cols {
-		panic("square matrix must be provided")
-	}
-	if b.cols != 1 || b.rows != A.rows {
-		panic(fmt.Sprintf("b vector has the wrong shape: %d x %d", b.rows, b.cols))
-	}
-	if x0.cols != 1 || x0.rows != A.rows {
-		panic(fmt.Sprintf("x0 vector has the wrong shape: %d x %d", x0.rows, x0.cols))
+// A can be dense or sparse. Stops once the change in x between iterations
+// drops below epsilon. Returns computed x along with convergence details,
+// and an error if the method does not converge, in which case x holds the
+// last iterate. Panics if arguments don't have the correct shape.
+func SolveGaussSeidel(A Matrix, b, x0 *M, epsilon float64, maxIterations uint, opts ...IterOption) (*M, *IterResult, error) {
+	return SolveSOR(A, b, x0, 1, epsilon, maxIterations, opts...)
+}
+
+// SolveSOR solves Ax = b using successive over-relaxation, i.e.
+// Gauss-Seidel with every update scaled by omega. Omega must be in (0, 2);
+// an omega of 0 picks a near-optimal value using EstimateOmega. A can be
+// dense or sparse. Stops once the change in x between iterations drops
+// below epsilon. Returns computed x along with convergence details, and
+// an error if the method does not converge, in which case x holds the
+// last iterate. Panics if arguments don't have the correct shape.
+func SolveSOR(A Matrix, b, x0 *M, omega, epsilon float64, maxIterations uint, opts ...IterOption) (*M, *IterResult, error) {
+	checkIterativeArgs(A, b, x0, epsilon)
+	mon := newMonitor(newIterSettings(opts), epsilon, maxIterations)
+	if omega == 0 {
+		var err error
+		omega, err = EstimateOmega(A)
+		if err != nil {
+			return nil, mon.res, mon.fail(err)
+		}
 	}
-	if epsilon < 0 {
-		panic("negative error margin")
+	if omega <= 0 || omega >= 2 {
+		panic(errorf(ErrArgument, "relaxation factor out of range: %g", omega))
 	}
 	xp := x0.Clone() // previous x
 	x := xp.Clone()  // start with x same as x0
+	if maxIterations == 0 {
+		mon.res.Reason = IterationLimit
+		return x, mon.res, mon.err()
+	}
 	var s1, s2 float64
-	for k := 0; k < int(maxIterations); k++ {
+	for {
 		// update x term by term
 		for i := 1; i <= x.rows; i++ {
 			s1, s2 = 0, 0
-			for j := 1; j < i; j++ {
-				s1 += A.Get(i, j) * x.Get(j, 1)
-			}
-			for j := i + 1; j <= x.rows; j++ {
-				s2 += A.Get(i, j) * xp.Get(j, 1)
-			}
-			x.Set(i, 1, b.Get(i, 1)-s1-s2)
+			A.DoRowNonZero(i, func(j int, v float64) {
+				if j < i {
+					s1 += v * x.Get(j, 1)
+				} else if j > i {
+					s2 += v * xp.Get(j, 1)
+				}
+			})
+			gs := (b.Get(i, 1) - s1 - s2) / A.Get(i, i)
+			x.Set(i, 1, (1-omega)*xp.Get(i, 1)+omega*gs)
 		}
 		// calculate error and stop if appropriate
 		s1 = 0
 		for i := 1; i <= x.rows; i++ {
 			s1 += math.Pow((x.Get(i, 1) - xp.Get(i, 1)), 2)
 		}
-		if math.Sqrt(s1) < epsilon {
-			return x, nil
+		if mon.step(math.Sqrt(s1)) {
+			return x, mon.res, mon.err()
 		}
 		x.CopyTo(xp)
 	}
-	return nil, nil
+}
+
+// omegaIterations bounds the power iteration in EstimateOmega.
+const omegaIterations = 200
+
+// EstimateOmega returns a near-optimal SOR relaxation factor for A,
+// 2 / (1 + sqrt(1 - r^2)), where r is the spectral radius of the Jacobi
+// iteration matrix estimated by power iteration. The formula is exact for
+// consistently ordered matrices, like those coming from finite difference
+// discretisations. Panics if A is not square, returns error if A has
+// zeroes on the diagonal or the Jacobi iteration would not converge.
+func EstimateOmega(A Matrix) (float64, error) {
+	n := checkSquare(A)
+	diag := make([]float64, n)
+	for i := 1; i <= n; i++ {
+		diag[i-1] = A.Get(i, i)
+		if diag[i-1] == 0 {
+			return 0, errorf(ErrSingular, "zero on diagonal at %d, %d", i, i)
+		}
+	}
+	// J = -D^-1 (A - D)
+	jacobi := func(x *M) *M {
+		y := New(n, 1)
+		for i := 1; i <= n; i++ {
+			var s float64
+			A.DoRowNonZero(i, func(j int, v float64) {
+				if j != i {
+					s -= v * x.data[j-1]
+				}
+			})
+			y.data[i-1] = s / diag[i-1]
+		}
+		return y
+	}
+	x := ones(n)
+	var rho float64
+	for k := 0; k < omegaIterations; k++ {
+		// apply J twice per step, Jacobi matrices often have eigenvalues
+		// r and -r, which makes single step ratios oscillate
+		nx := math.Sqrt(dot(x, x))
+		y := jacobi(jacobi(x))
+		ny := math.Sqrt(dot(y, y))
+		if ny == 0 {
+			// nilpotent J, e.g. triangular A, Gauss-Seidel is optimal
+			return 1, nil
+		}
+		r := math.Sqrt(ny / nx)
+		for i := 0; i < n; i++ {
+			x.data[i] = y.data[i] / ny
+		}
+		if math.Abs(r-rho) < 1e-9 {
+			rho = r
+			break
+		}
+		rho = r
+	}
+	if rho >= 1 {
+		return 0, errorf(ErrNotConverged, "jacobi iteration does not converge, spectral radius %g", rho)
+	}
+	return 2 / (1 + math.Sqrt(1-rho*rho)), nil
+}
+
+// SolveCG solves Ax = b using the conjugate gradient method. A can be
+// dense or sparse and must be symmetric positive definite, as must the
+// preconditioner if one is given. Stops once the residual norm ||b - Ax||
+// drops below epsilon. Returns computed x along with convergence details,
+// and an error if the method does not converge, in which case x holds the
+// last iterate. Panics if arguments don't have the correct shape.
+func SolveCG(A Matrix, b, x0 *M, epsilon float64, maxIterations uint, opts ...IterOption) (*M, *IterResult, error) {
+	checkIterativeArgs(A, b, x0, epsilon)
+	settings := newIterSettings(opts)
+	mon := newMonitor(settings, epsilon, maxIterations)
+	x := x0.Clone()
+	r := residual(A, b, x)
+	z := settings.precond.Solve(r)
+	p := z.Clone()
+	rz := dot(r, z)
+	if mon.start(math.Sqrt(dot(r, r))) {
+		return x, mon.res, mon.err()
+	}
+	for {
+		Ap := mulVec(A, p)
+		pAp := dot(p, Ap)
+		if pAp <= 0 {
+			return x, mon.res, mon.fail(errorf(ErrNotPositiveDefinite, "matrix is not positive definite"))
+		}
+		alpha := rz / pAp
+		axpy(alpha, p, x)
+		axpy(-alpha, Ap, r)
+		if mon.step(math.Sqrt(dot(r, r))) {
+			return x, mon.res, mon.err()
+		}
+		// next direction is conjugate to all previous ones
+		z = settings.precond.Solve(r)
+		rzNew := dot(r, z)
+		beta := rzNew / rz
+		for i := 0; i < len(p.data); i++ {
+			p.data[i] = z.data[i] + beta*p.data[i]
+		}
+		rz = rzNew
+	}
+}
+
+// SolveBiCGSTAB solves Ax = b using the stabilized biconjugate gradient
+// method, which also works for non symmetric A. A can be dense or sparse.
+// A preconditioner, if given, is applied from the right. Stops once the
+// residual norm ||b - Ax|| drops below epsilon. Returns computed x along
+// with convergence details, and an error if the method breaks down or
+// does not converge, in which case x holds the last iterate. Panics if
+// arguments don't have the correct shape.
+func SolveBiCGSTAB(A Matrix, b, x0 *M, epsilon float64, maxIterations uint, opts ...IterOption) (*M, *IterResult, error) {
+	checkIterativeArgs(A, b, x0, epsilon)
+	settings := newIterSettings(opts)
+	mon := newMonitor(settings, epsilon, maxIterations)
+	x := x0.Clone()
+	r := residual(A, b, x)
+	if mon.start(math.Sqrt(dot(r, r))) {
+		return x, mon.res, mon.err()
+	}
+	rhat := r.Clone()
+	rho, alpha, omega := 1.0, 1.0, 1.0
+	v := New(x.rows, 1)
+	p := New(x.rows, 1)
+	for {
+		rhoNew := dot(rhat, r)
+		if rhoNew == 0 || omega == 0 {
+			return x, mon.res, mon.fail(errorf(ErrNotConverged, "method broke down"))
+		}
+		beta := (rhoNew / rho) * (alpha / omega)
+		for i := 0; i < len(p.data); i++ {
+			p.data[i] = r.data[i] + beta*(p.data[i]-omega*v.data[i])
+		}
+		phat := settings.precond.Solve(p)
+		v = mulVec(A, phat)
+		rv := dot(rhat, v)
+		if rv == 0 {
+			return x, mon.res, mon.fail(errorf(ErrNotConverged, "method broke down"))
+		}
+		alpha = rhoNew / rv
+		// half step, s is the residual after moving along p
+		s := r.Clone()
+		axpy(-alpha, v, s)
+		axpy(alpha, phat, x)
+		if sres := math.Sqrt(dot(s, s)); sres < epsilon {
+			mon.step(sres)
+			return x, mon.res, mon.err()
+		}
+		shat := settings.precond.Solve(s)
+		t := mulVec(A, shat)
+		tt := dot(t, t)
+		if tt == 0 {
+			return x, mon.res, mon.fail(errorf(ErrNotConverged, "method broke down"))
+		}
+		omega = dot(t, s) / tt
+		axpy(omega, shat, x)
+		r = s
+		axpy(-omega, t, r)
+		if mon.step(math.Sqrt(dot(r, r))) {
+			return x, mon.res, mon.err()
+		}
+		rho = rhoNew
+	}
+}
+
+// SolveGMRES solves Ax = b using the generalized minimal residual method,
+// restarted every restart iterations to bound memory use. A can be dense
+// or sparse. A restart of 0 selects min(n, 30). A preconditioner, if
+// given, is applied from the right so the residual being minimised is
+// still the one of the original system. Stops once the residual norm
+// ||b - Ax|| drops below epsilon; within a restart cycle the residual is
+// the estimate GMRES maintains, at restarts it is computed exactly.
+// Returns computed x along with convergence details, and an error if the
+// method does not converge, in which case x holds the last iterate.
+// Panics if arguments don't have the correct shape.
+func SolveGMRES(A Matrix, b, x0 *M, epsilon float64, maxIterations, restart uint, opts ...IterOption) (*M, *IterResult, error) {
+	checkIterativeArgs(A, b, x0, epsilon)
+	settings := newIterSettings(opts)
+	mon := newMonitor(settings, epsilon, maxIterations)
+	n := x0.rows
+	m := int(restart)
+	if m == 0 || m > n {
+		m = n
+		if m > 30 {
+			m = 30
+		}
+	}
+	x := x0.Clone()
+	r := residual(A, b, x)
+	beta := math.Sqrt(dot(r, r))
+	if mon.start(beta) {
+		return x, mon.res, mon.err()
+	}
+	for {
+		// Arnoldi process builds an orthonormal Krylov basis V and the
+		// Hessenberg matrix H, which Givens rotations keep triangular so
+		// the least squares residual is available at every step
+		V := make([]*M, m+1)
+		H := New(m+1, m)
+		cs := make([]float64, m+1)
+		sn := make([]float64, m+1)
+		g := New(m+1, 1)
+		g.Set(1, 1, beta)
+		V[0] = r
+		for i := 0; i < n; i++ {
+			r.data[i] /= beta
+		}
+		stop := false
+		j := 1
+		for ; j <= m && !stop; j++ {
+			w := mulVec(A, settings.precond.Solve(V[j-1]))
+			for i := 1; i <= j; i++ {
+				h := dot(w, V[i-1])
+				H.Set(i, j, h)
+				axpy(-h, V[i-1], w)
+			}
+			h := math.Sqrt(dot(w, w))
+			H.Set(j+1, j, h)
+			if h != 0 {
+				for i := 0; i < n; i++ {
+					w.data[i] /= h
+				}
+			}
+			V[j] = w
+			for i := 1; i < j; i++ {
+				a, c := H.Get(i, j), H.Get(i+1, j)
+				H.Set(i, j, cs[i]*a+sn[i]*c)
+				H.Set(i+1, j, -sn[i]*a+cs[i]*c)
+			}
+			d := math.Hypot(H.Get(j, j), h)
+			if d == 0 {
+				return x, mon.res, mon.fail(errorf(ErrNotConverged, "method broke down"))
+			}
+			cs[j], sn[j] = H.Get(j, j)/d, h/d
+			H.Set(j, j, d)
+			H.Set(j+1, j, 0)
+			g.Set(j+1, 1, -sn[j]*g.Get(j, 1))
+			g.Set(j, 1, cs[j]*g.Get(j, 1))
+			// a zero h means x is exact within the current Krylov space
+			stop = mon.step(math.Abs(g.Get(j+1, 1))) || h == 0
+		}
+		// solve the j-1 square triangular system and update x
+		y, err := SolveTriangular(H.View(1, 1, j-1, j-1), g.View(1, 1, j-1, 1), TriUpper)
+		if err != nil {
+			return x, mon.res, mon.fail(err)
+		}
+		u := New(n, 1)
+		for i := 1; i < j; i++ {
+			axpy(y.Get(i, 1), V[i-1], u)
+		}
+		axpy(1, settings.precond.Solve(u), x)
+		// the estimate can be off in finite precision, so confirm
+		// convergence with the true residual before returning
+		r = residual(A, b, x)
+		beta = math.Sqrt(dot(r, r))
+		if beta < epsilon {
+			mon.res.Reason = Converged
+			return x, mon.res, nil
+		}
+		if stop {
+			switch mon.res.Reason {
+			case Running, Converged:
+				// the estimate was off or the Krylov space ran out,
+				// restart from the true residual
+				mon.res.Reason = Running
+			default:
+				return x, mon.res, mon.err()
+			}
+		}
+		if mon.res.Iterations >= maxIterations {
+			mon.res.Reason = IterationLimit
+			return x, mon.res, mon.err()
+		}
+	}
+}
+
+// checkIterativeArgs panics if the arguments of an iterative solver don't
+// have the correct shape.
+func checkIterativeArgs(A Matrix, b, x0 *M, epsilon float64) {
+	rows, cols := A.Dims()
+	if rows != cols {
+		panic(errorf(ErrShape, "square matrix must be provided"))
+	}
+	if b.cols != 1 || b.rows != rows {
+		panic(errorf(ErrShape, "b vector has the wrong shape: %d x %d", b.rows, b.cols))
+	}
+	if x0.cols != 1 || x0.rows != rows {
+		panic(errorf(ErrShape, "x0 vector has the wrong shape: %d x %d", x0.rows, x0.cols))
+	}
+	if epsilon < 0 {
+		panic(errorf(ErrArgument, "negative error margin"))
+	}
+}
+
+// ones builds a column vector of n ones.
+func ones(n int) *M {
+	x := New(n, 1)
+	for i := 0; i < n; i++ {
+		x.data[i] = 1
+	}
+	return x
+}
+
+// mulVec computes A*x for column vector x.
+func mulVec(A Matrix, x *M) *M {
+	rows, _ := A.Dims()
+	y := New(rows, 1)
+	for i := 1; i <= rows; i++ {
+		var s float64
+		A.DoRowNonZero(i, func(j int, v float64) {
+			s += v * x.data[(j-1)*x.rowStride]
+		})
+		y.data[i-1] = s
+	}
+	return y
+}
+
+// residual computes b - A*x.
+func residual(A Matrix, b, x *M) *M {
+	r := mulVec(A, x)
+	for i := 0; i < r.rows; i++ {
+		r.data[i] = b.data[i*b.rowStride] - r.data[i]
+	}
+	return r
+}
+
+// dot computes the dot product of two column vectors.
+func dot(x, y *M) float64 {
+	var s float64
+	for i := 0; i < x.rows; i++ {
+		s += x.data[i*x.rowStride] * y.data[i*y.rowStride]
+	}
+	return s
+}
+
+// axpy adds alpha*x to y, in place.
+func axpy(alpha float64, x, y *M) {
+	for i := 0; i < x.rows; i++ {
+		y.data[i*y.rowStride] += alpha * x.data[i*x.rowStride]
+	}
 }
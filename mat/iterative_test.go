@@ -2,6 +2,7 @@ package mat
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,7 +35,7 @@ func TestJacobiSolver(t *testing.T) {
 	for i, tc := range tcs {
 		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
 			assert.NotPanics(t, func() {
-				_, err := SolveJacobi(tc.A, tc.b, tc.x0, tc.epsilon, tc.maxIters)
+				_, _, err := SolveJacobi(tc.A, tc.b, tc.x0, tc.epsilon, tc.maxIters)
 				if !tc.converges {
 					assert.Error(t, err)
 					return
@@ -72,7 +73,7 @@ func TestGaussSeidelSolver(t *testing.T) {
 	for i, tc := range tcs {
 		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
 			assert.NotPanics(t, func() {
-				_, err := SolveGaussSeidel(tc.A, tc.b, tc.x0, tc.epsilon, tc.maxIters)
+				_, _, err := SolveGaussSeidel(tc.A, tc.b, tc.x0, tc.epsilon, tc.maxIters)
 				if !tc.converges {
 					assert.Error(t, err)
 					return
@@ -82,3 +83,158 @@ func TestGaussSeidelSolver(t *testing.T) {
 		})
 	}
 }
+
+// poisson builds the sparse matrix of the 1D Poisson equation with n
+// unknowns, which is symmetric positive definite and poorly conditioned.
+func poisson(n int) *CSR {
+	c := NewCOO(n, n)
+	for i := 1; i <= n; i++ {
+		c.Add(i, i, 2)
+		if i > 1 {
+			c.Add(i, i-1, -1)
+		}
+		if i < n {
+			c.Add(i, i+1, -1)
+		}
+	}
+	return c.ToCSR()
+}
+
+func TestCGSolver(t *testing.T) {
+	A := New(3, 3, 4, 1, 0, 1, 3, -1, 0, -1, 2)
+	b := Vec(1, 2, 3)
+	x, res, err := SolveCG(A, b, Vec(0, 0, 0), 1e-10, 10)
+	assert.NoError(t, err)
+	assert.True(t, res.Iterations <= 3)
+	assert.True(t, res.Residual() < 1e-10)
+	assertInDelta(t, b, A.Mul(x), 1e-9)
+
+	P := poisson(200)
+	b = New(200, 1)
+	b.Set(100, 1, 1)
+	x, _, err = SolveCG(P, b, New(200, 1), 1e-10, 400)
+	assert.NoError(t, err)
+	assertInDelta(t, b, P.Mul(x), 1e-9)
+}
+
+func TestCGNotPositiveDefinite(t *testing.T) {
+	A := New(2, 2, 1, 0, 0, -1)
+	_, res, err := SolveCG(A, Vec(1, 1), Vec(0, 0), 1e-10, 10)
+	assert.Error(t, err)
+	assert.Equal(t, BrokeDown, res.Reason)
+}
+
+func TestBiCGSTABSolver(t *testing.T) {
+	A := New(3, 3, 4, 1, 2, -1, 3, 0, 2, 1, 5)
+	b := Vec(1, 2, 3)
+	x, res, err := SolveBiCGSTAB(A, b, Vec(0, 0, 0), 1e-10, 50)
+	assert.NoError(t, err)
+	assert.True(t, res.Residual() < 1e-10)
+	assertInDelta(t, b, A.Mul(x), 1e-9)
+	_, res, err = SolveBiCGSTAB(poisson(200), ones(200), New(200, 1), 1e-10, 5)
+	assert.Error(t, err)
+	assert.Equal(t, IterationLimit, res.Reason)
+	assert.Equal(t, uint(5), res.Iterations)
+	assert.Len(t, res.Residuals, 5)
+
+	// A rotates b by 90 degrees, so the first step has no length
+	x, res, err = SolveBiCGSTAB(New(2, 2, 0, 1, -1, 0), Vec(1, 0), Vec(0, 0), 1e-10, 10)
+	assert.Error(t, err)
+	assert.Equal(t, BrokeDown, res.Reason)
+	assertInDelta(t, Vec(0, 0), x, 0)
+}
+
+func TestGMRESSolver(t *testing.T) {
+	A := New(3, 3, 4, 1, 2, -1, 3, 0, 2, 1, 5)
+	b := Vec(1, 2, 3)
+	for _, restart := range []uint{0, 1, 2} {
+		x, res, err := SolveGMRES(A, b, Vec(0, 0, 0), 1e-10, 100, restart)
+		assert.NoError(t, err)
+		assert.Equal(t, Converged, res.Reason)
+		assertInDelta(t, b, A.Mul(x), 1e-9)
+	}
+	P := poisson(100)
+	b = ones(100)
+	x, _, err := SolveGMRES(P, b, New(100, 1), 1e-10, 200, 100)
+	assert.NoError(t, err)
+	assertInDelta(t, b, P.Mul(x), 1e-9)
+}
+
+func TestSORSolver(t *testing.T) {
+	A := poisson(30)
+	b := ones(30)
+	_, _, err := SolveGaussSeidel(A, b, New(30, 1), 1e-8, 300)
+	assert.Error(t, err)
+	x, _, err := SolveSOR(A, b, New(30, 1), 0, 1e-8, 300)
+	assert.NoError(t, err)
+	assertInDelta(t, b, A.Mul(x), 1e-6)
+	x, _, err = SolveSOR(A, b, New(30, 1), 1.8, 1e-8, 300)
+	assert.NoError(t, err)
+	assertInDelta(t, b, A.Mul(x), 1e-6)
+	assert.Panics(t, func() {
+		SolveSOR(A, b, New(30, 1), 2.5, 1e-8, 300)
+	})
+}
+
+func TestEstimateOmega(t *testing.T) {
+	// spectral radius of the Jacobi matrix is cos(pi/(n+1)) for Poisson
+	rho := math.Cos(math.Pi / 31)
+	omega, err := EstimateOmega(poisson(30))
+	assert.NoError(t, err)
+	assert.InDelta(t, 2/(1+math.Sqrt(1-rho*rho)), omega, 0.01)
+	_, err = EstimateOmega(New(3, 3, 2, -1, 1, 2, 2, 2, -1, -1, 2))
+	assert.Error(t, err)
+}
+
+func TestIterationCallback(t *testing.T) {
+	A := poisson(30)
+	var seen []float64
+	stopAt := uint(4)
+	_, res, err := SolveCG(A, ones(30), New(30, 1), 1e-10, 100, WithCallback(func(k uint, r float64) bool {
+		seen = append(seen, r)
+		return k < stopAt
+	}))
+	assert.Error(t, err)
+	assert.Equal(t, Stopped, res.Reason)
+	assert.Equal(t, stopAt, res.Iterations)
+	assert.Equal(t, res.Residuals, seen)
+
+	// the iteration that converges is reported too
+	seen = nil
+	_, res, err = SolveCG(A, ones(30), New(30, 1), 1e-10, 100, WithCallback(func(k uint, r float64) bool {
+		seen = append(seen, r)
+		return true
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, Converged, res.Reason)
+	assert.Equal(t, res.Residuals, seen)
+}
+
+func TestDivergenceDetection(t *testing.T) {
+	// Jacobi iteration matrix has spectral radius 3, error triples each step
+	A := New(2, 2, 1, 3, 3, 1)
+	x, res, err := SolveJacobi(A, Vec(1, 1), Vec(0, 0), 1e-8, 1000, WithDivergenceLimit(1e6))
+	assert.Error(t, err)
+	assert.NotNil(t, x)
+	assert.Equal(t, Diverged, res.Reason)
+	assert.True(t, res.Iterations < 30)
+	// off by default
+	_, res, err = SolveJacobi(A, Vec(1, 1), Vec(0, 0), 1e-8, 50)
+	assert.Error(t, err)
+	assert.Equal(t, IterationLimit, res.Reason)
+}
+
+func TestStallDetection(t *testing.T) {
+	// Jacobi iteration matrix is a rotation, the step size never shrinks
+	A := New(2, 2, 1, -1, 1, 1)
+	_, res, err := SolveJacobi(A, Vec(1, 1), Vec(0, 0), 1e-8, 1000, WithStallLimit(10))
+	assert.Error(t, err)
+	assert.Equal(t, Stalled, res.Reason)
+	assert.Equal(t, "stalled", res.Reason.String())
+	assert.Equal(t, Running, (&IterResult{}).Reason)
+	// off by default
+	_, res, err = SolveJacobi(A, Vec(1, 1), Vec(0, 0), 1e-8, 1000)
+	assert.Error(t, err)
+	assert.Equal(t, IterationLimit, res.Reason)
+	assert.Equal(t, uint(1000), res.Iterations)
+}
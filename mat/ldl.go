@@ -0,0 +1,225 @@
+package mat
+
+import "math"
+
+// bkAlpha is the Bunch-Kaufman pivoting threshold, (1 + sqrt(17)) / 8,
+// which minimises the bound on element growth.
+var bkAlpha = (1 + math.Sqrt(17)) / 8
+
+// LDLFactorization is a factorization P'AP = LDL' of a symmetric matrix A,
+// where P is a permutation matrix, L is unit lower triangular and D is
+// block diagonal with blocks of size 1 or 2. Unlike Cholesky it works for
+// indefinite matrices.
+type LDLFactorization struct {
+	l, d *M
+	perm []int
+	// size of the diagonal block starting at each index, 0 for the second
+	// index of a 2 x 2 block
+	blocks []int
+}
+
+// FactorLDL computes the LDL' factorization of symmetrical matrix A without
+// pivoting, so D is diagonal and P is the identity. A is not mutated.
+// Panics if A is not square or symmetrical. Returns error if a zero pivot
+// is found; FactorBunchKaufman handles those.
+func FactorLDL(A *M) (*LDLFactorization, error) {
+	checkSymmetric(A)
+	n := A.rows
+	L := Eye(n)
+	D := New(n, n)
+	f := &LDLFactorization{l: L, d: D, perm: make([]int, n), blocks: make([]int, n)}
+	for j := 1; j <= n; j++ {
+		f.perm[j-1] = j
+		f.blocks[j-1] = 1
+		s := A.Get(j, j)
+		for k := 1; k < j; k++ {
+			s -= L.Get(j, k) * L.Get(j, k) * D.Get(k, k)
+		}
+		if s == 0 {
+			return nil, errorf(ErrSingular, "zero pivot found at %d, %d", j, j)
+		}
+		D.Set(j, j, s)
+		for i := j + 1; i <= n; i++ {
+			s := A.Get(i, j)
+			for k := 1; k < j; k++ {
+				s -= L.Get(i, k) * L.Get(j, k) * D.Get(k, k)
+			}
+			L.Set(i, j, s/D.Get(j, j))
+		}
+	}
+	return f, nil
+}
+
+// FactorBunchKaufman computes the LDL' factorization of symmetrical matrix
+// A using Bunch-Kaufman diagonal pivoting, which picks 1 x 1 or 2 x 2
+// pivots so that elements of L stay bounded. This makes it stable for
+// symmetric indefinite matrices such as KKT systems. A is not mutated.
+// Panics if A is not square or symmetrical. Returns error if A is
+// singular.
+func FactorBunchKaufman(A *M) (*LDLFactorization, error) {
+	checkSymmetric(A)
+	n := A.rows
+	a := A.Clone()
+	L := Eye(n)
+	D := New(n, n)
+	f := &LDLFactorization{l: L, d: D, perm: make([]int, n), blocks: make([]int, n)}
+	for i := range f.perm {
+		f.perm[i] = i + 1
+	}
+	for k := 1; k <= n; {
+		absakk := math.Abs(a.Get(k, k))
+		r, colmax := k, 0.0
+		for i := k + 1; i <= n; i++ {
+			if v := math.Abs(a.Get(i, k)); v > colmax {
+				r, colmax = i, v
+			}
+		}
+		if absakk == 0 && colmax == 0 {
+			return nil, errorf(ErrSingular, "singular matrix, zero column at %d", k)
+		}
+		size, swap := 1, k
+		if absakk < bkAlpha*colmax {
+			var rowmax float64
+			for j := k; j <= n; j++ {
+				if j != r {
+					rowmax = math.Max(rowmax, math.Abs(a.Get(r, j)))
+				}
+			}
+			switch {
+			case absakk*rowmax >= bkAlpha*colmax*colmax:
+				// keep the 1 x 1 pivot at k
+			case math.Abs(a.Get(r, r)) >= bkAlpha*rowmax:
+				swap = r
+			default:
+				size = 2
+			}
+		}
+		if size == 1 {
+			f.swap(a, k, swap)
+			d := a.Get(k, k)
+			D.Set(k, k, d)
+			f.blocks[k-1] = 1
+			for i := k + 1; i <= n; i++ {
+				L.Set(i, k, a.Get(i, k)/d)
+			}
+			for i := k + 1; i <= n; i++ {
+				for j := k + 1; j <= n; j++ {
+					a.Set(i, j, a.Get(i, j)-L.Get(i, k)*a.Get(j, k))
+				}
+			}
+			k++
+			continue
+		}
+		// 2 x 2 pivot on rows k and r, moved to k and k+1
+		f.swap(a, k+1, r)
+		e11, e21, e22 := a.Get(k, k), a.Get(k+1, k), a.Get(k+1, k+1)
+		det := e11*e22 - e21*e21
+		if det == 0 {
+			return nil, errorf(ErrSingular, "singular 2 x 2 pivot at %d, %d", k, k)
+		}
+		D.Set(k, k, e11)
+		D.Set(k+1, k, e21)
+		D.Set(k, k+1, e21)
+		D.Set(k+1, k+1, e22)
+		f.blocks[k-1], f.blocks[k] = 2, 0
+		for i := k + 2; i <= n; i++ {
+			// [l1 l2] = [a(i, k) a(i, k+1)] * inv(E)
+			a1, a2 := a.Get(i, k), a.Get(i, k+1)
+			L.Set(i, k, (a1*e22-a2*e21)/det)
+			L.Set(i, k+1, (a2*e11-a1*e21)/det)
+		}
+		for i := k + 2; i <= n; i++ {
+			for j := k + 2; j <= n; j++ {
+				a.Set(i, j, a.Get(i, j)-L.Get(i, k)*a.Get(j, k)-L.Get(i, k+1)*a.Get(j, k+1))
+			}
+		}
+		k += 2
+	}
+	return f, nil
+}
+
+// swap exchanges index i and j symmetrically in the working matrix a, in
+// the already computed columns of L and in the permutation.
+func (f *LDLFactorization) swap(a *M, i, j int) {
+	if i == j {
+		return
+	}
+	a.SwapRows(i, j)
+	a.SwapCols(i, j)
+	for c := 1; c < i && c < j; c++ {
+		li, lj := f.l.Get(i, c), f.l.Get(j, c)
+		f.l.Set(i, c, lj)
+		f.l.Set(j, c, li)
+	}
+	f.perm[i-1], f.perm[j-1] = f.perm[j-1], f.perm[i-1]
+}
+
+// L returns the unit lower triangular factor.
+func (f *LDLFactorization) L() *M {
+	return f.l.Clone()
+}
+
+// D returns the block diagonal factor.
+func (f *LDLFactorization) D() *M {
+	return f.d.Clone()
+}
+
+// P returns the permutation matrix, such that P'AP = LDL'.
+func (f *LDLFactorization) P() *M {
+	P := New(len(f.perm), len(f.perm))
+	for i, r := range f.perm {
+		P.Set(r, i+1, 1)
+	}
+	return P
+}
+
+// Solve solves Ax = b. Panics if b is not a vector the size of A.
+func (f *LDLFactorization) Solve(b *M) *M {
+	n := len(f.perm)
+	if b.cols != 1 || b.rows != n {
+		panic(errorf(ErrShape, "b vector has the wrong shape: %d x %d", b.rows, b.cols))
+	}
+	// LDL'y = P'b, then x = Py
+	y := New(n, 1)
+	for i := 1; i <= n; i++ {
+		s := b.Get(f.perm[i-1], 1)
+		for j := 1; j < i; j++ {
+			s -= f.l.Get(i, j) * y.Get(j, 1)
+		}
+		y.Set(i, 1, s)
+	}
+	for i := 1; i <= n; i++ {
+		switch f.blocks[i-1] {
+		case 1:
+			y.Set(i, 1, y.Get(i, 1)/f.d.Get(i, i))
+		case 2:
+			e11, e21, e22 := f.d.Get(i, i), f.d.Get(i+1, i), f.d.Get(i+1, i+1)
+			det := e11*e22 - e21*e21
+			y1, y2 := y.Get(i, 1), y.Get(i+1, 1)
+			y.Set(i, 1, (e22*y1-e21*y2)/det)
+			y.Set(i+1, 1, (e11*y2-e21*y1)/det)
+		}
+	}
+	for i := n; i >= 1; i-- {
+		s := y.Get(i, 1)
+		for j := i + 1; j <= n; j++ {
+			s -= f.l.Get(j, i) * y.Get(j, 1)
+		}
+		y.Set(i, 1, s)
+	}
+	x := New(n, 1)
+	for i := 1; i <= n; i++ {
+		x.Set(f.perm[i-1], 1, y.Get(i, 1))
+	}
+	return x
+}
+
+// checkSymmetric panics if A is not square or not symmetrical.
+func checkSymmetric(A *M) {
+	if A.rows != A.cols {
+		panic(errorf(ErrShape, "need square matrix for LDL' decomposition"))
+	}
+	if !A.Equals(A.Transpose()) {
+		panic(errorf(ErrNotSymmetric, "need symmetrical matrix for LDL' decomposition"))
+	}
+}
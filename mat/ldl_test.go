@@ -0,0 +1,59 @@
+package mat
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFactorLDL(t *testing.T) {
+	// KKT matrix of a small equality constrained quadratic program
+	A := New(3, 3, 2, 0, 1, 0, 2, 1, 1, 1, 0)
+	f, err := FactorLDL(A)
+	assert.NoError(t, err)
+	assertInDelta(t, A, f.L().Mul(f.D()).Mul(f.L().Transpose()), 1e-12)
+	assertInDelta(t, Eye(3), f.P(), 0)
+	x := f.Solve(Vec(1, 2, 3))
+	assertInDelta(t, Vec(1, 2, 3), A.Mul(x), 1e-12)
+
+	_, err = FactorLDL(New(2, 2, 0, 1, 1, 0))
+	assert.Error(t, err)
+	assert.Panics(t, func() { FactorLDL(New(2, 2, 1, 2, 3, 4)) })
+}
+
+func TestFactorBunchKaufman(t *testing.T) {
+	tcs := []*M{
+		New(2, 2, 0, 1, 1, 0),
+		New(3, 3, 2, 0, 1, 0, 2, 1, 1, 1, 0),
+		New(4, 4,
+			0, 1, 2, 3,
+			1, 0, 4, 5,
+			2, 4, 0, 6,
+			3, 5, 6, 0),
+		New(4, 4,
+			1e-3, 1, 0, 0,
+			1, 1e-3, 2, 0,
+			0, 2, -3, 1,
+			0, 0, 1, 4),
+		New(3, 3, 25, 15, -5, 15, 18, 0, -5, 0, 11),
+	}
+	for i, A := range tcs {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			f, err := FactorBunchKaufman(A)
+			assert.NoError(t, err)
+			P, L := f.P(), f.L()
+			assertInDelta(t, P.Transpose().Mul(A).Mul(P), L.Mul(f.D()).Mul(L.Transpose()), 1e-12)
+			for r := 1; r <= L.rows; r++ {
+				assert.Equal(t, 1.0, L.Get(r, r))
+			}
+			b := ones(A.rows)
+			assertInDelta(t, b, A.Mul(f.Solve(b)), 1e-9)
+		})
+	}
+	f, err := FactorBunchKaufman(New(2, 2, 0, 1, 1, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 0}, f.blocks)
+	_, err = FactorBunchKaufman(New(3, 3, 1, 2, 0, 2, 4, 0, 0, 0, 0))
+	assert.Error(t, err)
+}
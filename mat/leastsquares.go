@@ -0,0 +1,40 @@
+package mat
+
+import "math"
+
+// SolveLeastSquares finds the x minimising ||Ax - b|| for an overdetermined
+// system using the Householder QR decomposition of A. Returns x together
+// with the norm of the residual Ax - b. Panics if A has more columns than
+// rows or b is not a vector with as many rows as A. Returns error if A
+// does not have full column rank.
+func SolveLeastSquares(A, b *M) (*M, float64, error) {
+	if A.cols > A.rows {
+		panic(errorf(ErrShape, "least squares solver needs at least as many rows as columns"))
+	}
+	if b.cols != 1 || b.rows != A.rows {
+		panic(errorf(ErrShape, "b vector has the wrong shape: %d x %d", b.rows, b.cols))
+	}
+	Q, R := HouseholderQR(A, false)
+	c := Q.Transpose().Mul(b)
+	// with A = QR, ||Ax - b|| = ||Rx - Q'b||, so the top part of Q'b is
+	// matched exactly and the bottom part is the residual
+	var max float64
+	for i := 1; i <= A.cols; i++ {
+		max = math.Max(max, math.Abs(R.Get(i, i)))
+	}
+	tol := float64(A.rows) * max * machEps
+	for i := 1; i <= A.cols; i++ {
+		if math.Abs(R.Get(i, i)) <= tol {
+			return nil, 0, errorf(ErrSingular, "rank deficient matrix, column %d is dependent", i)
+		}
+	}
+	x, err := SolveTriangular(R.View(1, 1, A.cols, A.cols), c.View(1, 1, A.cols, 1), TriUpper)
+	if err != nil {
+		return nil, 0, err
+	}
+	var s float64
+	for i := A.cols + 1; i <= A.rows; i++ {
+		s += c.Get(i, 1) * c.Get(i, 1)
+	}
+	return x, math.Sqrt(s), nil
+}
@@ -0,0 +1,39 @@
+package mat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveLeastSquares(t *testing.T) {
+	// fit y = a + bx through (0, 6), (1, 0), (2, 0)
+	A := New(3, 2, 1, 0, 1, 1, 1, 2)
+	b := Vec(6, 0, 0)
+	x, res, err := SolveLeastSquares(A, b)
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(5, -3), x, 1e-9)
+	assert.InDelta(t, math.Sqrt(6), res, 1e-9)
+}
+
+func TestSolveLeastSquaresSquare(t *testing.T) {
+	a := New(3, 3, 2, 1, -1, -3, -1, 2, -2, 1, 2)
+	b := Vec(8, -11, -3)
+	x, res, err := SolveLeastSquares(a, b)
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(2, 3, -1), x, 1e-9)
+	assert.InDelta(t, 0, res, 1e-9)
+}
+
+func TestSolveLeastSquaresRankDeficient(t *testing.T) {
+	A := New(3, 2, 1, 2, 2, 4, 3, 6)
+	_, _, err := SolveLeastSquares(A, Vec(1, 2, 3))
+	assert.Error(t, err)
+}
+
+func TestSolveLeastSquaresWide(t *testing.T) {
+	assert.Panics(t, func() {
+		SolveLeastSquares(New(2, 3), Vec(1, 2))
+	})
+}
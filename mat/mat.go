@@ -7,22 +7,40 @@ import (
 	"strings"
 )
 
+// Matrix is the read-only view of a matrix shared by dense and sparse
+// storage. Operations that only need to look at elements, such as the
+// iterative solvers, accept any Matrix.
+type Matrix interface {
+	// Dims returns the number of rows and columns.
+	Dims() (int, int)
+	// Get returns the element at row/col, 1 based.
+	Get(row, col int) float64
+	// DoRowNonZero calls fn with the column and value of every non zero
+	// element in row, in increasing column order.
+	DoRowNonZero(row int, fn func(col int, v float64))
+}
+
 // M is a matrix
 type M struct {
 	rows int
 	cols int
 	data []float64
+	// element (i, j) is at data[(i-1)*rowStride+(j-1)*colStride]; a new
+	// matrix is stored by columns, with strides 1 and rows, views can
+	// have any strides
+	rowStride int
+	colStride int
 }
 
 // New initializes a matrix of specified size. If initial values
 // are provided, these will be used to fill the matrix line by
 // line. Only up to rows * cols values will be taken into account.
-// Panics if asked to create a 0 size matrix.
+// Panics if asked to create a matrix with a zero or negative size.
 func New(rows, cols int, xs ...float64) *M {
-	if rows == 0 || cols == 0 {
-		panic(fmt.Sprintf("invalid matrix size (%d x %d)", rows, cols))
+	if rows <= 0 || cols <= 0 {
+		panic(errorf(ErrShape, "invalid matrix size (%d x %d)", rows, cols))
 	}
-	m := &M{rows: rows, cols: cols}
+	m := &M{rows: rows, cols: cols, rowStride: 1, colStride: rows}
 	m.data = make([]float64, rows*cols)
 	for i, j, k := 1, 1, 0; i <= m.rows && j <= m.cols && k < len(xs); k++ {
 		m.Set(i, j, xs[k])
@@ -40,28 +58,59 @@ func New(rows, cols int, xs ...float64) *M {
 // Panics if indices exceed matrix size.
 func (m *M) Set(row, col int, value float64) {
 	if row > m.rows || row <= 0 || col > m.cols || col <= 0 {
-		panic(fmt.Sprintf("invalid matrix indices: %d, %d", row, col))
+		panic(errorf(ErrIndex, "invalid matrix indices: %d, %d", row, col))
 	}
-	m.data[m.rows*(col-1)+row-1] = value
+	m.data[m.at(row, col)] = value
 }
 
 // Get value at row/col. Panics if indices exceed matrix size.
 func (m *M) Get(row, col int) float64 {
 	if row > m.rows || row <= 0 || col > m.cols || col <= 0 {
-		panic(fmt.Sprintf("invalid matrix indices: %d, %d", row, col))
+		panic(errorf(ErrIndex, "invalid matrix indices: %d, %d", row, col))
+	}
+	return m.data[m.at(row, col)]
+}
+
+// at returns the position of element row/col in m.data, without checks.
+func (m *M) at(row, col int) int {
+	return (row-1)*m.rowStride + (col-1)*m.colStride
+}
+
+// contiguous tells whether m.data holds exactly the elements of m, by
+// columns, as for a newly allocated matrix.
+func (m *M) contiguous() bool {
+	return m.rowStride == 1 && m.colStride == m.rows && len(m.data) == m.rows*m.cols
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (m *M) Dims() (int, int) {
+	return m.rows, m.cols
+}
+
+// DoRowNonZero calls fn for every non zero element in row. Panics if
+// row is invalid.
+func (m *M) DoRowNonZero(row int, fn func(col int, v float64)) {
+	if row > m.rows || row <= 0 {
+		panic(errorf(ErrIndex, "invalid row number: %d", row))
+	}
+	for j := 1; j <= m.cols; j++ {
+		if v := m.data[m.at(row, j)]; v != 0 {
+			fn(j, v)
+		}
 	}
-	return m.data[m.rows*(col-1)+row-1]
 }
 
 // Equals compares matrices for equality. Only makes sense for matrices
 // with identical dimensions, panics otherwise.
 func (m *M) Equals(other *M) bool {
 	if m.rows != other.rows || m.cols != other.cols {
-		panic(fmt.Sprintf("trying to compare matrices of different sizes: %dx%d, %dx%d", m.rows, m.cols, other.rows, other.cols))
+		panic(errorf(ErrShape, "trying to compare matrices of different sizes: %dx%d, %dx%d", m.rows, m.cols, other.rows, other.cols))
 	}
-	for i := 0; i < len(m.data); i++ {
-		if !almostEqual(m.data[i], other.data[i]) {
-			return false
+	for j := 1; j <= m.cols; j++ {
+		for i := 1; i <= m.rows; i++ {
+			if !almostEqual(m.data[m.at(i, j)], other.data[other.at(i, j)]) {
+				return false
+			}
 		}
 	}
 	return true
@@ -71,61 +120,96 @@ func (m *M) Equals(other *M) bool {
 // The two matrices must have the same number or rows.
 func (m *M) Augment(other *M) *M {
 	if m.rows != other.rows {
-		panic("can't augment matrices of different heights")
+		panic(errorf(ErrShape, "can't augment matrices of different heights"))
 	}
 	aug := New(m.rows, m.cols+other.cols)
-	mlen := len(m.data)
-	for i := 0; i < mlen; i++ {
-		aug.data[i] = m.data[i]
-	}
-	for i := 0; i < len(other.data); i++ {
-		aug.data[mlen+i] = other.data[i]
-	}
+	m.CopyTo(aug.View(1, 1, m.rows, m.cols))
+	other.CopyTo(aug.View(1, m.cols+1, m.rows, aug.cols))
 	return aug
 }
 
 // Slice the matrix, returning a submatrix from given coordinates.
-// The returned slice does not share memory with the original matrix.
+// The returned slice does not share memory with the original matrix,
+// see View for one that does.
 // Panics if invalid indices are provided.
 func (m *M) Slice(r1, c1, r2, c2 int) *M {
+	return m.View(r1, c1, r2, c2).Clone()
+}
+
+// View returns the submatrix between the given coordinates without
+// copying: it shares memory with m, so changes to either one show in
+// the other. Panics if invalid indices are provided.
+func (m *M) View(r1, c1, r2, c2 int) *M {
 	if r1 < 1 || c1 < 1 || r2 > m.rows || c2 > m.cols || r2 < r1 || c2 < c1 {
-		panic(fmt.Sprintf("invalid indices: %d %d %d %d", r1, c1, r2, c2))
-	}
-	a := New(r2-r1+1, c2-c1+1)
-	k := 0
-	for i := c1; i <= c2; i++ {
-		for j := r1; j <= r2; j++ {
-			a.data[k] = m.Get(j, i)
-			k++
-		}
+		panic(errorf(ErrIndex, "invalid indices: %d %d %d %d", r1, c1, r2, c2))
 	}
-	return a
+	return m.view(m.at(r1, c1), r2-r1+1, c2-c1+1, m.rowStride, m.colStride)
+}
+
+// Row returns row i as a 1 x cols view sharing memory with m. Panics if
+// the row number is invalid.
+func (m *M) Row(i int) *M {
+	if i <= 0 || i > m.rows {
+		panic(errorf(ErrIndex, "invalid row number: %d", i))
+	}
+	return m.View(i, 1, i, m.cols)
+}
+
+// Col returns column j as a rows x 1 view sharing memory with m. Panics
+// if the column number is invalid.
+func (m *M) Col(j int) *M {
+	if j <= 0 || j > m.cols {
+		panic(errorf(ErrIndex, "invalid column number: %d", j))
+	}
+	return m.View(1, j, m.rows, j)
+}
+
+// Diag returns the main diagonal as a column vector view sharing memory
+// with m.
+func (m *M) Diag() *M {
+	n := m.rows
+	if m.cols < n {
+		n = m.cols
+	}
+	return m.view(0, n, 1, m.rowStride+m.colStride, m.colStride)
+}
+
+// T returns the transpose of m as a view sharing memory with m. See
+// Transpose for a copy.
+func (m *M) T() *M {
+	return m.view(0, m.cols, m.rows, m.colStride, m.rowStride)
+}
+
+// view builds a rows x cols matrix on m.data starting at off, with the
+// given strides.
+func (m *M) view(off, rows, cols, rowStride, colStride int) *M {
+	end := off + (rows-1)*rowStride + (cols-1)*colStride + 1
+	return &M{rows: rows, cols: cols, data: m.data[off:end], rowStride: rowStride, colStride: colStride}
 }
 
 // Mul multiplies two matrices. If the first has a rows and b columns
 // the second must have b rows and c colums. Result has a rows and c cols.
-func (m *M) Mul(other *M) *M {
-	if m.cols != other.rows {
-		panic(fmt.Sprintf("can't multiply matrices of shapes %dx%d and %dx%d", m.rows, m.cols, other.rows, other.cols))
-	}
-	res := New(m.rows, other.cols)
-	var x float64
-	for i := 1; i <= res.rows; i++ {
-		for j := 1; j <= res.cols; j++ {
-			x = 0
-			for k := 1; k <= m.cols; k++ {
-				x += m.Get(i, k) * other.Get(k, j)
-			}
-			res.Set(i, j, x)
-		}
+// The second operand can be sparse, result is always dense. See
+// MulParallel for spreading the work of large products over goroutines.
+func (m *M) Mul(other Matrix) *M {
+	orows, ocols := other.Dims()
+	if m.cols != orows {
+		panic(errorf(ErrShape, "can't multiply matrices of shapes %dx%d and %dx%d", m.rows, m.cols, orows, ocols))
+	}
+	res := New(m.rows, ocols)
+	o, ok := other.(*M)
+	if !ok {
+		mulSparse(m, other, res, 0, res.rows)
+		return res
 	}
+	mulBlocked(m, o, res, 0, res.rows, 0, res.cols)
 	return res
 }
 
 // SwapRows in place. Panics if row numbers are invalid.
 func (m *M) SwapRows(i, j int) {
 	if i <= 0 || i > m.rows || j <= 0 || j > m.rows {
-		panic(fmt.Sprintf("invalid row numbers: %d %d", i, j))
+		panic(errorf(ErrIndex, "invalid row numbers: %d %d", i, j))
 	}
 	var aux float64
 	for c := 1; c <= m.cols; c++ {
@@ -138,7 +222,7 @@ func (m *M) SwapRows(i, j int) {
 // SwapCols in place. Panics if column numbers are invalid.
 func (m *M) SwapCols(i, j int) {
 	if i <= 0 || i > m.cols || j <= 0 || j > m.cols {
-		panic(fmt.Sprintf("invalid column numbers: %d %d", i, j))
+		panic(errorf(ErrIndex, "invalid column numbers: %d %d", i, j))
 	}
 	var aux float64
 	for r := 1; r <= m.rows; r++ {
@@ -162,6 +246,7 @@ func (m *M) MaxIndex(r1, c1, r2, c2 int) (int, int) {
 	for i := r1; i <= r2; i++ {
 		for j := c1; j <= c2; j++ {
 			if math.Abs(m.Get(i, j)) > max {
+				max = math.Abs(m.Get(i, j))
 				r, c = i, j
 			}
 		}
@@ -169,7 +254,7 @@ func (m *M) MaxIndex(r1, c1, r2, c2 int) (int, int) {
 	return r, c
 }
 
-// Transpose matrix returning a new matrix.
+// Transpose matrix returning a new matrix. See T for a view.
 func (m *M) Transpose() *M {
 	t := New(m.cols, m.rows)
 	for i := 1; i <= m.rows; i++ {
@@ -183,18 +268,24 @@ func (m *M) Transpose() *M {
 // Clone a matrix, returning an identical matrix which shares no memory.
 func (m *M) Clone() *M {
 	n := New(m.rows, m.cols)
-	for i := 0; i < len(m.data); i++ {
-		n.data[i] = m.data[i]
-	}
+	m.CopyTo(n)
 	return n
 }
 
 // CopyTo copies a matrix's contents to another. Panics if they are not the same size.
 func (m *M) CopyTo(other *M) {
 	if m.rows != other.rows || m.cols != other.cols {
-		panic(fmt.Sprintf("trying to copy between matrices of different sizes: %dx%d, %dx%d", m.rows, m.cols, other.rows, other.cols))
+		panic(errorf(ErrShape, "trying to copy between matrices of different sizes: %dx%d, %dx%d", m.rows, m.cols, other.rows, other.cols))
+	}
+	if m.contiguous() && other.contiguous() {
+		copy(other.data, m.data)
+		return
+	}
+	for j := 1; j <= m.cols; j++ {
+		for i := 1; i <= m.rows; i++ {
+			other.data[other.at(i, j)] = m.data[m.at(i, j)]
+		}
 	}
-	copy(other.data, m.data)
 }
 
 // String makes matrices printable
@@ -232,7 +323,7 @@ func Rand(rows, cols int) *M {
 // Vec creates a column vector (n x 1 matrix) given xs.
 func Vec(xs ...float64) *M {
 	if len(xs) == 0 {
-		panic("can't create empty vector")
+		panic(errorf(ErrShape, "can't create empty vector"))
 	}
 	return New(len(xs), 1, xs...)
 }
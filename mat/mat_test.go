@@ -122,6 +122,10 @@ func TestMaxIndex(t *testing.T) {
 	r, c = m.MaxIndex(2, 2, 3, 3)
 	assert.Equal(t, r, 3)
 	assert.Equal(t, c, 3)
+	m = Vec(1, -5, 3)
+	r, c = m.MaxIndex(1, 1, 3, 1)
+	assert.Equal(t, r, 2)
+	assert.Equal(t, c, 1)
 }
 
 func TestTranspose(t *testing.T) {
@@ -147,3 +151,92 @@ func TestAlmostEqual(t *testing.T) {
 		})
 	}
 }
+
+// assertInDelta checks that two matrices of the same shape match element
+// by element within an absolute margin.
+func assertInDelta(t *testing.T, expected, actual *M, delta float64) {
+	t.Helper()
+	if !assert.Equal(t, expected.rows, actual.rows) || !assert.Equal(t, expected.cols, actual.cols) {
+		return
+	}
+	for i := 1; i <= expected.rows; i++ {
+		for j := 1; j <= expected.cols; j++ {
+			assert.InDelta(t, expected.Get(i, j), actual.Get(i, j), delta, "element %d, %d", i, j)
+		}
+	}
+}
+
+func TestView(t *testing.T) {
+	m := New(3, 4, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+	v := m.View(2, 2, 3, 4)
+	assert.True(t, v.Equals(New(2, 3, 6, 7, 8, 10, 11, 12)))
+	// writes go both ways
+	v.Set(1, 1, -6)
+	assert.Equal(t, -6.0, m.Get(2, 2))
+	m.Set(3, 4, -12)
+	assert.Equal(t, -12.0, v.Get(2, 3))
+	assert.Panics(t, func() { v.Get(3, 1) })
+	assert.Panics(t, func() { m.View(2, 2, 4, 4) })
+
+	// views of views
+	w := v.View(2, 2, 2, 3)
+	assert.True(t, w.Equals(New(1, 2, 11, -12)))
+	w.Set(1, 1, 0)
+	assert.Equal(t, 0.0, m.Get(3, 3))
+
+	// Slice and Clone still copy
+	s := m.Slice(1, 1, 2, 2)
+	s.Set(1, 1, 100)
+	assert.Equal(t, 1.0, m.Get(1, 1))
+	c := v.Clone()
+	c.Set(1, 1, 100)
+	assert.Equal(t, -6.0, m.Get(2, 2))
+	assert.True(t, c.contiguous())
+}
+
+func TestRowColDiag(t *testing.T) {
+	m := New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	assert.True(t, m.Row(2).Equals(New(1, 3, 4, 5, 6)))
+	assert.True(t, m.Col(3).Equals(Vec(3, 6, 9)))
+	assert.True(t, m.Diag().Equals(Vec(1, 5, 9)))
+	assert.True(t, New(2, 3, 1, 2, 3, 4, 5, 6).Diag().Equals(Vec(1, 5)))
+	assert.Panics(t, func() { m.Row(4) })
+	assert.Panics(t, func() { m.Col(0) })
+
+	Vec(0, 0, 0).CopyTo(m.Col(2))
+	m.Row(1).Set(1, 3, -3)
+	d := m.Diag()
+	d.Set(3, 1, -9)
+	assert.True(t, m.Equals(New(3, 3, 1, 0, -3, 4, 0, 6, 7, 0, -9)))
+}
+
+func TestT(t *testing.T) {
+	m := New(2, 3, 1, 2, 3, 4, 5, 6)
+	mt := m.T()
+	assert.True(t, mt.Equals(m.Transpose()))
+	mt.Set(3, 1, 30)
+	assert.Equal(t, 30.0, m.Get(1, 3))
+	assert.True(t, mt.T().Equals(m))
+	assert.True(t, mt.Row(2).Equals(New(1, 2, 2, 5)))
+	assert.True(t, mt.View(2, 1, 3, 2).Equals(New(2, 2, 2, 5, 30, 6)))
+	assert.True(t, mt.Mul(m).Equals(m.Transpose().Mul(m)))
+	assert.True(t, m.Augment(mt.T()).Equals(m.Augment(m)))
+}
+
+func TestViewsInSolvers(t *testing.T) {
+	// system matrix and right hand side live in one augmented matrix
+	a := New(3, 4, 2, 1, -1, 8, -3, -1, 2, -11, -2, 1, 2, -3)
+	A, b := a.View(1, 1, 3, 3), a.Col(4)
+	x, err := SolveGaussPartial(A, b)
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(2, 3, -1), x, 1e-12)
+	x, err = SolveGaussFull(A.T().T(), b)
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(2, 3, -1), x, 1e-12)
+
+	S := New(3, 3, 4, 1, 0, 1, 4, 1, 0, 1, 4)
+	rhs := New(2, 3, 1, 2, 3, 0, 0, 0).Row(1).T()
+	x, _, err = SolveCG(S, rhs, New(3, 1), 1e-12, 10)
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(1, 2, 3), S.Mul(x), 1e-9)
+}
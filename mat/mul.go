@@ -0,0 +1,113 @@
+package mat
+
+import (
+	"runtime"
+	"sync"
+)
+
+// mulBlock is the edge of the square blocks products are computed on,
+// small enough for a block of each operand and of the result to stay in
+// cache together.
+const mulBlock = 64
+
+// mulBlocked adds m*o to rows i1+1 to i2 and columns j1+1 to j2 of res,
+// which must be newly allocated. It walks the data slices directly, one block at a time, with
+// the innermost loop running down columns of m and res, which are
+// contiguous. Each element is still summed in increasing k order, so
+// results match the plain triple loop exactly.
+func mulBlocked(m, o, res *M, i1, i2, j1, j2 int) {
+	p := m.cols
+	for jb := j1; jb < j2; jb += mulBlock {
+		jend := minInt(jb+mulBlock, j2)
+		for kb := 0; kb < p; kb += mulBlock {
+			kend := minInt(kb+mulBlock, p)
+			for ib := i1; ib < i2; ib += mulBlock {
+				iend := minInt(ib+mulBlock, i2)
+				for j := jb; j < jend; j++ {
+					c := res.data[j*res.colStride+ib : j*res.colStride+iend]
+					for k := kb; k < kend; k++ {
+						b := o.data[k*o.rowStride+j*o.colStride]
+						off := k * m.colStride
+						if m.rowStride == 1 {
+							a := m.data[off+ib : off+iend]
+							for i, v := range a {
+								c[i] += v * b
+							}
+							continue
+						}
+						for i := range c {
+							c[i] += m.data[off+(ib+i)*m.rowStride] * b
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// mulSparse adds m*other to rows i1+1 to i2 of res, which must be newly
+// allocated, walking the non zero elements of other only.
+func mulSparse(m *M, other Matrix, res *M, i1, i2 int) {
+	orows, _ := other.Dims()
+	for k := 1; k <= orows; k++ {
+		other.DoRowNonZero(k, func(j int, v float64) {
+			for i := i1; i < i2; i++ {
+				res.data[res.rows*(j-1)+i] += m.Get(i+1, k) * v
+			}
+		})
+	}
+}
+
+// MulParallel multiplies m by a dense or sparse matrix like Mul, splitting
+// the result in tiles of up to 64 x 64 elements that are shared out
+// between up to workers goroutines. Sparse operands are only split by
+// rows, as their elements are walked a row at a time. A workers value
+// <= 0 uses one per CPU, as given by GOMAXPROCS. The result is identical
+// to that of Mul. Panics if shapes don't match.
+func (m *M) MulParallel(other Matrix, workers int) *M {
+	orows, ocols := other.Dims()
+	if m.cols != orows {
+		panic(errorf(ErrShape, "can't multiply matrices of shapes %dx%d and %dx%d", m.rows, m.cols, orows, ocols))
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	res := New(m.rows, ocols)
+	o, dense := other.(*M)
+	rowTiles, colTiles := (res.rows+mulBlock-1)/mulBlock, 1
+	if dense {
+		colTiles = (res.cols + mulBlock - 1) / mulBlock
+	}
+	tiles := make(chan int, rowTiles*colTiles)
+	for t := 0; t < cap(tiles); t++ {
+		tiles <- t
+	}
+	close(tiles)
+	workers = minInt(workers, cap(tiles))
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for t := range tiles {
+				i1 := t % rowTiles * mulBlock
+				i2 := minInt(i1+mulBlock, res.rows)
+				if !dense {
+					mulSparse(m, other, res, i1, i2)
+					continue
+				}
+				j1 := t / rowTiles * mulBlock
+				mulBlocked(m, o, res, i1, i2, j1, minInt(j1+mulBlock, res.cols))
+			}
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
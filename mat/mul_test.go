@@ -0,0 +1,105 @@
+package mat
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveMul is the plain triple loop Mul used to be, kept as a reference.
+func naiveMul(m, o *M) *M {
+	res := New(m.rows, o.cols)
+	var x float64
+	for i := 1; i <= res.rows; i++ {
+		for j := 1; j <= res.cols; j++ {
+			x = 0
+			for k := 1; k <= m.cols; k++ {
+				x += m.Get(i, k) * o.Get(k, j)
+			}
+			res.Set(i, j, x)
+		}
+	}
+	return res
+}
+
+func TestMulBlocked(t *testing.T) {
+	// sizes around and across block boundaries
+	shapes := [][3]int{{1, 1, 1}, {3, 5, 2}, {64, 64, 64}, {65, 63, 130}, {150, 70, 1}, {1, 200, 129}, {300, 40, 3}}
+	for _, s := range shapes {
+		t.Run(fmt.Sprintf("%dx%dx%d", s[0], s[1], s[2]), func(t *testing.T) {
+			a, b := Rand(s[0], s[1]), Rand(s[1], s[2])
+			want := naiveMul(a, b)
+			assert.Equal(t, want.data, a.Mul(b).data)
+			for _, workers := range []int{0, 1, 3, 16} {
+				assert.Equal(t, want.data, a.MulParallel(b, workers).data)
+			}
+		})
+	}
+}
+
+func TestMulViews(t *testing.T) {
+	a, b := Rand(90, 80), Rand(80, 100)
+	av, bv := a.View(5, 3, 74, 72), b.View(3, 11, 72, 90)
+	want := naiveMul(av, bv)
+	assert.Equal(t, want.data, av.Mul(bv).data)
+	assert.Equal(t, want.data, av.MulParallel(bv, 4).data)
+	// transposed views have a row stride other than 1
+	assert.Equal(t, naiveMul(a.T(), a).data, a.T().Mul(a).data)
+	assert.Equal(t, naiveMul(a.T(), a).data, a.T().MulParallel(a, 2).data)
+	assert.Panics(t, func() { a.MulParallel(a, 2) })
+}
+
+func TestMulParallelSparse(t *testing.T) {
+	c := NewCOO(70, 5)
+	for i := 1; i <= 70; i += 3 {
+		c.Add(i, i%5+1, float64(i))
+	}
+	a := Rand(200, 70)
+	want := a.Mul(c.ToCSR())
+	for _, workers := range []int{0, 1, 3} {
+		assert.Equal(t, want.data, a.MulParallel(c.ToCSR(), workers).data)
+		assert.Equal(t, want.data, a.MulParallel(c.ToCSC(), workers).data)
+	}
+	assert.Equal(t, naiveMul(a, c.ToCSR().Dense()).data, want.data)
+	assert.Panics(t, func() { a.MulParallel(c.ToCSR().Transpose(), 2) })
+}
+
+func benchmarkMul(b *testing.B, n int, mul func(x, y *M) *M) {
+	x, y := Rand(n, n), Rand(n, n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mul(x, y)
+	}
+}
+
+func BenchmarkMulNaive64(b *testing.B)  { benchmarkMul(b, 64, naiveMul) }
+func BenchmarkMulNaive256(b *testing.B) { benchmarkMul(b, 256, naiveMul) }
+func BenchmarkMulNaive512(b *testing.B) { benchmarkMul(b, 512, naiveMul) }
+
+func BenchmarkMul64(b *testing.B)  { benchmarkMul(b, 64, func(x, y *M) *M { return x.Mul(y) }) }
+func BenchmarkMul256(b *testing.B) { benchmarkMul(b, 256, func(x, y *M) *M { return x.Mul(y) }) }
+func BenchmarkMul512(b *testing.B) { benchmarkMul(b, 512, func(x, y *M) *M { return x.Mul(y) }) }
+
+func BenchmarkMulParallel256(b *testing.B) {
+	benchmarkMul(b, 256, func(x, y *M) *M { return x.MulParallel(y, 0) })
+}
+func BenchmarkMulParallel512(b *testing.B) {
+	benchmarkMul(b, 512, func(x, y *M) *M { return x.MulParallel(y, 0) })
+}
+
+func BenchmarkMulVec2048(b *testing.B) {
+	x, y := Rand(2048, 2048), Rand(2048, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Mul(y)
+	}
+}
+
+func BenchmarkMulParallelVec2048(b *testing.B) {
+	x, y := Rand(2048, 2048), Rand(2048, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.MulParallel(y, 0)
+	}
+}
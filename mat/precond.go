@@ -0,0 +1,293 @@
+package mat
+
+import (
+	"math"
+	"sort"
+)
+
+// Preconditioner approximates a matrix A by one that is cheap to invert.
+// Solve returns z such that Pz = r for the preconditioning matrix P,
+// without modifying r.
+type Preconditioner interface {
+	Solve(r *M) *M
+}
+
+// identity is the trivial preconditioner used when none is given.
+type identity struct{}
+
+func (identity) Solve(r *M) *M {
+	return r.Clone()
+}
+
+// JacobiPreconditioner uses the diagonal of A.
+type JacobiPreconditioner struct {
+	diag []float64
+}
+
+// NewJacobiPreconditioner builds a diagonal preconditioner for square
+// matrix A. Panics if A is not square, returns error if A has zeroes on
+// its diagonal.
+func NewJacobiPreconditioner(A Matrix) (*JacobiPreconditioner, error) {
+	n := checkSquare(A)
+	p := &JacobiPreconditioner{diag: make([]float64, n)}
+	for i := 1; i <= n; i++ {
+		d := A.Get(i, i)
+		if d == 0 {
+			return nil, errorf(ErrSingular, "zero on diagonal at %d, %d", i, i)
+		}
+		p.diag[i-1] = d
+	}
+	return p, nil
+}
+
+// Solve divides r by the diagonal of A, element by element.
+func (p *JacobiPreconditioner) Solve(r *M) *M {
+	z := r.Clone()
+	for i := range p.diag {
+		z.data[i] /= p.diag[i]
+	}
+	return z
+}
+
+// SSORPreconditioner uses one forward and one backward SOR sweep, which
+// corresponds to P = (D/w + L) (D/w)^-1 (D/w + U) / (2-w) for the
+// splitting A = L + D + U. It is symmetric when A is.
+type SSORPreconditioner struct {
+	A     Matrix
+	omega float64
+	diag  []float64
+}
+
+// NewSSORPreconditioner builds an SSOR preconditioner for square matrix A
+// with relaxation factor omega, which must be in (0, 2). Panics if A is
+// not square or omega is out of range, returns error if A has zeroes on
+// its diagonal.
+func NewSSORPreconditioner(A Matrix, omega float64) (*SSORPreconditioner, error) {
+	n := checkSquare(A)
+	if omega <= 0 || omega >= 2 {
+		panic(errorf(ErrArgument, "relaxation factor out of range: %g", omega))
+	}
+	p := &SSORPreconditioner{A: A, omega: omega, diag: make([]float64, n)}
+	for i := 1; i <= n; i++ {
+		d := A.Get(i, i)
+		if d == 0 {
+			return nil, errorf(ErrSingular, "zero on diagonal at %d, %d", i, i)
+		}
+		p.diag[i-1] = d / omega
+	}
+	return p, nil
+}
+
+// Solve applies the forward and backward sweeps to r.
+func (p *SSORPreconditioner) Solve(r *M) *M {
+	n := len(p.diag)
+	z := r.Clone()
+	// forward: (D/w + L) y = r
+	for i := 1; i <= n; i++ {
+		s := z.data[i-1]
+		p.A.DoRowNonZero(i, func(j int, v float64) {
+			if j < i {
+				s -= v * z.data[j-1]
+			}
+		})
+		z.data[i-1] = s / p.diag[i-1]
+	}
+	// scale: multiply by D/w and 2-w
+	for i := 0; i < n; i++ {
+		z.data[i] *= p.diag[i] * (2 - p.omega)
+	}
+	// backward: (D/w + U) z = y
+	for i := n; i >= 1; i-- {
+		s := z.data[i-1]
+		p.A.DoRowNonZero(i, func(j int, v float64) {
+			if j > i {
+				s -= v * z.data[j-1]
+			}
+		})
+		z.data[i-1] = s / p.diag[i-1]
+	}
+	return z
+}
+
+// ILUPreconditioner is an incomplete LU factorization of A with no fill
+// in: L and U only keep the elements that are non zero in A, which keeps
+// them as sparse as A itself.
+type ILUPreconditioner struct {
+	lu   sparseRows
+	diag []int // position of the diagonal element in each row
+}
+
+// NewILUPreconditioner computes the ILU(0) factorization of square matrix
+// A. It follows the same elimination as LU, except updates outside the
+// sparsity pattern of A are dropped. Panics if A is not square, returns
+// error if a zero pivot is found.
+func NewILUPreconditioner(A Matrix) (*ILUPreconditioner, error) {
+	n := checkSquare(A)
+	lu := newSparseRows(A)
+	p := &ILUPreconditioner{lu: lu, diag: make([]int, n)}
+	for i := 1; i <= n; i++ {
+		d := lu.find(i, i)
+		if d < 0 || lu.vals[i-1][d] == 0 {
+			return nil, errorf(ErrSingular, "zero pivot found at %d, %d", i, i)
+		}
+		p.diag[i-1] = d
+	}
+	for i := 2; i <= n; i++ {
+		cols, vals := lu.cols[i-1], lu.vals[i-1]
+		for a := 0; a < len(cols) && cols[a] < i; a++ {
+			k := cols[a]
+			// save scaling factor in L, then eliminate using row k of U
+			vals[a] /= lu.vals[k-1][p.diag[k-1]]
+			for b := a + 1; b < len(cols); b++ {
+				if c := lu.find(k, cols[b]); c >= 0 {
+					vals[b] -= vals[a] * lu.vals[k-1][c]
+				}
+			}
+		}
+		if vals[p.diag[i-1]] == 0 {
+			return nil, errorf(ErrSingular, "zero pivot found at %d, %d", i, i)
+		}
+	}
+	return p, nil
+}
+
+// Solve computes z = U^-1 L^-1 r by forward and back substitution.
+func (p *ILUPreconditioner) Solve(r *M) *M {
+	n := len(p.diag)
+	z := r.Clone()
+	for i := 1; i <= n; i++ {
+		cols, vals := p.lu.cols[i-1], p.lu.vals[i-1]
+		for a := 0; a < p.diag[i-1]; a++ {
+			z.data[i-1] -= vals[a] * z.data[cols[a]-1]
+		}
+	}
+	for i := n; i >= 1; i-- {
+		cols, vals := p.lu.cols[i-1], p.lu.vals[i-1]
+		d := p.diag[i-1]
+		for a := d + 1; a < len(cols); a++ {
+			z.data[i-1] -= vals[a] * z.data[cols[a]-1]
+		}
+		z.data[i-1] /= vals[d]
+	}
+	return z
+}
+
+// ICPreconditioner is an incomplete Cholesky factorization A ~ BB' with no
+// fill in, B keeping the sparsity pattern of the lower triangle of A.
+type ICPreconditioner struct {
+	b sparseRows // rows of B, each ending with its diagonal element
+}
+
+// NewICPreconditioner computes the IC(0) factorization of symmetric
+// positive definite matrix A. It follows the same formulas as Cholesky,
+// except elements outside the sparsity pattern of A are dropped. Only the
+// lower triangle of A is read. Panics if A is not square, returns error
+// if a non positive diagonal value is found.
+func NewICPreconditioner(A Matrix) (*ICPreconditioner, error) {
+	n := checkSquare(A)
+	b := sparseRows{cols: make([][]int, n), vals: make([][]float64, n)}
+	for i := 1; i <= n; i++ {
+		A.DoRowNonZero(i, func(j int, v float64) {
+			if j <= i {
+				b.cols[i-1] = append(b.cols[i-1], j)
+				b.vals[i-1] = append(b.vals[i-1], v)
+			}
+		})
+		cols, vals := b.cols[i-1], b.vals[i-1]
+		if len(cols) == 0 || cols[len(cols)-1] != i {
+			return nil, errorf(ErrNotPositiveDefinite, "negative diagonal value at %d, %d", i, i)
+		}
+		d := len(cols) - 1
+		for a := 0; a < d; a++ {
+			// B(i, k) = (A(i, k) - sum B(i, j)*B(k, j)) / B(k, k), j < k
+			k := cols[a]
+			kcols, kvals := b.cols[k-1], b.vals[k-1]
+			var s float64
+			for x, y := 0, 0; x < a && y < len(kcols)-1; {
+				switch {
+				case cols[x] < kcols[y]:
+					x++
+				case cols[x] > kcols[y]:
+					y++
+				default:
+					s += vals[x] * kvals[y]
+					x++
+					y++
+				}
+			}
+			vals[a] = (vals[a] - s) / kvals[len(kvals)-1]
+		}
+		var s float64
+		for a := 0; a < d; a++ {
+			s += vals[a] * vals[a]
+		}
+		s = vals[d] - s
+		if s <= 0 {
+			return nil, errorf(ErrNotPositiveDefinite, "negative diagonal value at %d, %d", i, i)
+		}
+		vals[d] = math.Sqrt(s)
+	}
+	return &ICPreconditioner{b: b}, nil
+}
+
+// Solve computes z = B'^-1 B^-1 r by forward and back substitution.
+func (p *ICPreconditioner) Solve(r *M) *M {
+	n := len(p.b.cols)
+	z := r.Clone()
+	for i := 1; i <= n; i++ {
+		cols, vals := p.b.cols[i-1], p.b.vals[i-1]
+		d := len(cols) - 1
+		for a := 0; a < d; a++ {
+			z.data[i-1] -= vals[a] * z.data[cols[a]-1]
+		}
+		z.data[i-1] /= vals[d]
+	}
+	// B' is walked by columns of B, i.e. the stored rows backwards
+	for i := n; i >= 1; i-- {
+		cols, vals := p.b.cols[i-1], p.b.vals[i-1]
+		d := len(cols) - 1
+		z.data[i-1] /= vals[d]
+		for a := 0; a < d; a++ {
+			z.data[cols[a]-1] -= vals[a] * z.data[i-1]
+		}
+	}
+	return z
+}
+
+// sparseRows is a mutable row by row copy of a matrix's non zero elements,
+// used as working storage by the incomplete factorizations.
+type sparseRows struct {
+	cols [][]int
+	vals [][]float64
+}
+
+func newSparseRows(A Matrix) sparseRows {
+	n, _ := A.Dims()
+	s := sparseRows{cols: make([][]int, n), vals: make([][]float64, n)}
+	for i := 1; i <= n; i++ {
+		A.DoRowNonZero(i, func(j int, v float64) {
+			s.cols[i-1] = append(s.cols[i-1], j)
+			s.vals[i-1] = append(s.vals[i-1], v)
+		})
+	}
+	return s
+}
+
+// find returns the position of column col in row, or -1 if not stored.
+func (s sparseRows) find(row, col int) int {
+	cols := s.cols[row-1]
+	k := sort.SearchInts(cols, col)
+	if k < len(cols) && cols[k] == col {
+		return k
+	}
+	return -1
+}
+
+// checkSquare panics if A is not square, returning its size otherwise.
+func checkSquare(A Matrix) int {
+	rows, cols := A.Dims()
+	if rows != cols {
+		panic(errorf(ErrShape, "square matrix must be provided"))
+	}
+	return rows
+}
@@ -0,0 +1,99 @@
+package mat
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreconditionedCG(t *testing.T) {
+	A := poisson(100)
+	b := ones(100)
+	_, plain, err := SolveCG(A, b, New(100, 1), 1e-10, 500)
+	assert.NoError(t, err)
+
+	jacobi, err := NewJacobiPreconditioner(A)
+	assert.NoError(t, err)
+	ssor, err := NewSSORPreconditioner(A, 1.5)
+	assert.NoError(t, err)
+	ic, err := NewICPreconditioner(A)
+	assert.NoError(t, err)
+	tcs := []struct {
+		P     Preconditioner
+		fewer bool
+	}{
+		{P: jacobi, fewer: false},
+		{P: ssor, fewer: true},
+		{P: ic, fewer: true},
+	}
+	for i, tc := range tcs {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			x, res, err := SolveCG(A, b, New(100, 1), 1e-10, 500, WithPreconditioner(tc.P))
+			assert.NoError(t, err)
+			assertInDelta(t, b, A.Mul(x), 1e-8)
+			if tc.fewer {
+				assert.True(t, res.Iterations < plain.Iterations, "%d iterations, %d without preconditioner", res.Iterations, plain.Iterations)
+			}
+		})
+	}
+}
+
+func TestPreconditionedNonSymmetric(t *testing.T) {
+	c := NewCOO(50, 50)
+	for i := 1; i <= 50; i++ {
+		c.Add(i, i, 3)
+		if i > 1 {
+			c.Add(i, i-1, -2)
+		}
+		if i < 50 {
+			c.Add(i, i+1, -0.5)
+		}
+	}
+	A := c.ToCSR()
+	b := ones(50)
+	ilu, err := NewILUPreconditioner(A)
+	assert.NoError(t, err)
+	// no fill in happens for tridiagonal matrices, so ILU(0) is exact
+	x, res, err := SolveGMRES(A, b, New(50, 1), 1e-10, 100, 10, WithPreconditioner(ilu))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), res.Iterations)
+	assertInDelta(t, b, A.Mul(x), 1e-9)
+	x, _, err = SolveBiCGSTAB(A, b, New(50, 1), 1e-10, 100, WithPreconditioner(ilu))
+	assert.NoError(t, err)
+	assertInDelta(t, b, A.Mul(x), 1e-9)
+}
+
+func TestILUMatchesLU(t *testing.T) {
+	A := New(3, 3, 4, 1, 2, 2, 5, 1, 1, 2, 6)
+	p, err := NewILUPreconditioner(A)
+	assert.NoError(t, err)
+	r := Vec(1, 2, 3)
+	x, err := SolveGaussPartial(A, r)
+	assert.NoError(t, err)
+	assertInDelta(t, x, p.Solve(r), 1e-12)
+}
+
+func TestICMatchesCholesky(t *testing.T) {
+	// dense, so nothing gets dropped
+	A := New(3, 3, 25, 15, -5, 15, 18, 2, -5, 2, 11)
+	p, err := NewICPreconditioner(A)
+	assert.NoError(t, err)
+	r := Vec(1, 2, 3)
+	x, err := SolveGaussPartial(A, r)
+	assert.NoError(t, err)
+	assertInDelta(t, x, p.Solve(r), 1e-12)
+}
+
+func TestPreconditionerErrors(t *testing.T) {
+	A := New(2, 2, 0, 1, 1, 0)
+	_, err := NewJacobiPreconditioner(A)
+	assert.Error(t, err)
+	_, err = NewILUPreconditioner(A)
+	assert.Error(t, err)
+	_, err = NewICPreconditioner(New(2, 2, 1, 2, 2, 1))
+	assert.Error(t, err)
+	assert.Panics(t, func() {
+		NewSSORPreconditioner(Eye(2), 2)
+	})
+}
@@ -0,0 +1,127 @@
+package mat
+
+import "math"
+
+// NormType selects the matrix norm computed by Norm.
+type NormType int
+
+// Supported norms. For column vectors Norm1 and NormInf give the usual
+// vector norms, while Norm2 and NormFrobenius both give the Euclidean
+// length.
+const (
+	// Norm1 is the maximum absolute column sum.
+	Norm1 NormType = iota
+	// Norm2 is the largest singular value.
+	Norm2
+	// NormInf is the maximum absolute row sum.
+	NormInf
+	// NormFrobenius is the square root of the sum of squared elements.
+	NormFrobenius
+)
+
+// Norm computes the norm of the matrix. The 2-norm of a matrix that is
+// not a vector needs an SVD and is NaN if that does not converge. Panics
+// if t is not a supported norm.
+func (m *M) Norm(t NormType) float64 {
+	switch t {
+	case Norm1:
+		var max float64
+		for j := 1; j <= m.cols; j++ {
+			var s float64
+			for i := 1; i <= m.rows; i++ {
+				s += math.Abs(m.Get(i, j))
+			}
+			max = math.Max(max, s)
+		}
+		return max
+	case NormInf:
+		var max float64
+		for i := 1; i <= m.rows; i++ {
+			var s float64
+			for j := 1; j <= m.cols; j++ {
+				s += math.Abs(m.Get(i, j))
+			}
+			max = math.Max(max, s)
+		}
+		return max
+	case Norm2:
+		if m.rows == 1 || m.cols == 1 {
+			return frobenius(m)
+		}
+		_, S, _, err := SVD(m)
+		if err != nil {
+			return math.NaN()
+		}
+		return S.Get(1, 1)
+	case NormFrobenius:
+		return frobenius(m)
+	}
+	panic(errorf(ErrArgument, "unknown norm type: %d", t))
+}
+
+// Trace returns the sum of the diagonal elements. Panics if the matrix is
+// not square.
+func (m *M) Trace() float64 {
+	if m.rows != m.cols {
+		panic(errorf(ErrShape, "trace only defined for square matrices"))
+	}
+	var s float64
+	for i := 1; i <= m.rows; i++ {
+		s += m.Get(i, i)
+	}
+	return s
+}
+
+// Det computes the determinant of square matrix A as the product of the
+// pivots of its LU decomposition with partial pivoting, with the sign
+// flipped once for each row swap. Panics if A is not square.
+func Det(A *M) float64 {
+	if A.rows != A.cols {
+		panic(errorf(ErrShape, "determinant only defined for square matrices"))
+	}
+	lu, _, det := plu(A)
+	for i := 1; i <= lu.rows; i++ {
+		det *= lu.Get(i, i)
+	}
+	return det
+}
+
+// Rank computes the rank of A by gaussian elimination with full pivoting,
+// counting pivots larger than tol in absolute value. A tol <= 0 selects a
+// default of max(m, n) * max|A(i, j)| * machine epsilon. A is not mutated.
+func Rank(A *M, tol float64) int {
+	a := A.Clone()
+	if tol <= 0 {
+		tol = rankTolerance(a)
+	}
+	rank := 0
+	for k := 1; k <= a.rows && k <= a.cols; k++ {
+		rmax, cmax := a.MaxIndex(k, k, a.rows, a.cols)
+		pivot := a.Get(rmax, cmax)
+		if math.Abs(pivot) <= tol {
+			// everything left is negligible
+			break
+		}
+		rank++
+		a.SwapRows(rmax, k)
+		a.SwapCols(cmax, k)
+		for i := k + 1; i <= a.rows; i++ {
+			f := a.Get(i, k) / pivot
+			for j := k + 1; j <= a.cols; j++ {
+				a.Set(i, j, a.Get(i, j)-a.Get(k, j)*f)
+			}
+		}
+	}
+	return rank
+}
+
+// rankTolerance is the default tolerance below which pivots are considered
+// zero, max(m, n) * max|A(i, j)| * machine epsilon.
+func rankTolerance(A *M) float64 {
+	r, c := A.MaxIndex(1, 1, A.rows, A.cols)
+	dim := A.rows
+	if A.cols > dim {
+		dim = A.cols
+	}
+	return float64(dim) * math.Abs(A.Get(r, c)) * machEps
+}
@@ -0,0 +1,90 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNorm(t *testing.T) {
+	tcs := []struct {
+		A                        *M
+		one, two, inf, frobenius float64
+	}{
+		{
+			A:         New(2, 2, 1, -2, 3, 4),
+			one:       6,
+			two:       5.116672736016927,
+			inf:       7,
+			frobenius: math.Sqrt(30),
+		},
+		{
+			A:         Vec(3, -4),
+			one:       7,
+			two:       5,
+			inf:       4,
+			frobenius: 5,
+		},
+		{
+			A:         New(1, 2, 3, -4),
+			one:       4,
+			two:       5,
+			inf:       7,
+			frobenius: 5,
+		},
+	}
+	for i, tc := range tcs {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			assert.InDelta(t, tc.one, tc.A.Norm(Norm1), 1e-12)
+			assert.InDelta(t, tc.two, tc.A.Norm(Norm2), 1e-12)
+			assert.InDelta(t, tc.inf, tc.A.Norm(NormInf), 1e-12)
+			assert.InDelta(t, tc.frobenius, tc.A.Norm(NormFrobenius), 1e-12)
+		})
+	}
+	assert.Panics(t, func() { Eye(2).Norm(NormType(42)) })
+}
+
+func TestTrace(t *testing.T) {
+	assert.Equal(t, 15.0, New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9).Trace())
+	assert.Panics(t, func() { New(2, 3).Trace() })
+}
+
+func TestDet(t *testing.T) {
+	tcs := []struct {
+		A   *M
+		det float64
+	}{
+		{A: New(1, 1, 7), det: 7},
+		{A: New(2, 2, 0, 1, 1, 0), det: -1},
+		{A: New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9), det: 0},
+		{A: New(3, 3, 2, 1, -1, -3, -1, 2, -2, 1, 2), det: -1},
+		{A: New(3, 3, 0, 0, 2, 0, 3, 0, 4, 0, 0), det: -24},
+	}
+	for i, tc := range tcs {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			assert.InDelta(t, tc.det, Det(tc.A), 1e-12)
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	tcs := []struct {
+		A    *M
+		tol  float64
+		rank int
+	}{
+		{A: Eye(3), rank: 3},
+		{A: New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9), rank: 2},
+		{A: New(2, 4, 1, 2, 3, 4, 2, 4, 6, 8), rank: 1},
+		{A: New(2, 2), rank: 0},
+		{A: New(2, 2, 1, 0, 0, 1e-6), rank: 2},
+		{A: New(2, 2, 1, 0, 0, 1e-6), tol: 1e-3, rank: 1},
+	}
+	for i, tc := range tcs {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			assert.Equal(t, tc.rank, Rank(tc.A, tc.tol))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package mat
+
+import "math"
+
+// RREF brings a copy of A to reduced row echelon form by gaussian
+// elimination with partial pivoting, returning it along with the pivot
+// columns. Values no larger than tol in absolute value are considered
+// zero; a tol <= 0 selects a default based on the size and largest element
+// of A. A is not mutated.
+func RREF(A *M, tol float64) (*M, []int) {
+	a := A.Clone()
+	if tol <= 0 {
+		tol = rankTolerance(a)
+	}
+	pivots := gaussPartialPivot(a, a.cols, tol)
+	for r := len(pivots); r >= 1; r-- {
+		p := pivots[r-1]
+		// scale pivot row so the pivot is 1
+		f := a.Get(r, p)
+		a.Set(r, p, 1)
+		for j := p + 1; j <= a.cols; j++ {
+			a.Set(r, j, a.Get(r, j)/f)
+		}
+		// transform all elements above pivot to zeroes
+		for i := 1; i < r; i++ {
+			f := a.Get(i, p)
+			a.Set(i, p, 0)
+			for j := p + 1; j <= a.cols; j++ {
+				a.Set(i, j, a.Get(i, j)-a.Get(r, j)*f)
+			}
+		}
+	}
+	// clear what elimination left below tolerance
+	for i := range a.data {
+		if math.Abs(a.data[i]) <= tol {
+			a.data[i] = 0
+		}
+	}
+	return a, pivots
+}
+
+// NullSpace returns a basis of the null space of A, the vectors x with
+// Ax = 0, as columns of a matrix. There is one column for each non-pivot
+// column of RREF(A), or nil is returned if the null space only holds the
+// zero vector. See RREF for tol.
+func NullSpace(A *M, tol float64) *M {
+	R, pivots := RREF(A, tol)
+	if len(pivots) == A.cols {
+		return nil
+	}
+	N := New(A.cols, A.cols-len(pivots))
+	k := 1
+	for j, r := 1, 0; j <= A.cols; j++ {
+		if r < len(pivots) && pivots[r] == j {
+			r++
+			continue
+		}
+		// set free variable j to 1 and the others to 0
+		N.Set(j, k, 1)
+		for i, p := range pivots {
+			N.Set(p, k, -R.Get(i+1, j))
+		}
+		k++
+	}
+	return N
+}
+
+// ColumnSpace returns a basis of the column space of A, made of the
+// columns of A that hold pivots in RREF(A). Returns nil if A is zero.
+// See RREF for tol.
+func ColumnSpace(A *M, tol float64) *M {
+	_, pivots := RREF(A, tol)
+	if len(pivots) == 0 {
+		return nil
+	}
+	C := New(A.rows, len(pivots))
+	for k, p := range pivots {
+		for i := 1; i <= A.rows; i++ {
+			C.Set(i, k+1, A.Get(i, p))
+		}
+	}
+	return C
+}
+
+// RowSpace returns a basis of the row space of A, made of the non-zero
+// rows of RREF(A), as columns of a matrix. Returns nil if A is zero.
+// See RREF for tol.
+func RowSpace(A *M, tol float64) *M {
+	R, pivots := RREF(A, tol)
+	if len(pivots) == 0 {
+		return nil
+	}
+	return R.View(1, 1, len(pivots), R.cols).Transpose()
+}
+
+// LeftNullSpace returns a basis of the left null space of A, the vectors y
+// with y'A = 0, as columns of a matrix. Returns nil if it only holds the
+// zero vector. See RREF for tol.
+func LeftNullSpace(A *M, tol float64) *M {
+	return NullSpace(A.Transpose(), tol)
+}
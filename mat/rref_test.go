@@ -0,0 +1,65 @@
+package mat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRREF(t *testing.T) {
+	tcs := []struct {
+		name   string
+		A      *M
+		R      *M
+		pivots []int
+	}{
+		{"invertible", New(2, 2, 2, 1, 1, 3), Eye(2), []int{1, 2}},
+		{"rank deficient",
+			New(3, 4, 1, 2, 1, 1, 2, 4, 0, 6, 1, 2, 2, -1),
+			New(3, 4, 1, 2, 0, 3, 0, 0, 1, -2, 0, 0, 0, 0),
+			[]int{1, 3}},
+		{"zero first column", New(2, 3, 0, 2, 4, 0, 1, 3), New(2, 3, 0, 1, 0, 0, 0, 1), []int{2, 3}},
+		{"zero", New(2, 2), New(2, 2), nil},
+		{"almost dependent", New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9), New(3, 3, 1, 0, -1, 0, 1, 2, 0, 0, 0), []int{1, 2}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			A := tc.A.Clone()
+			R, pivots := RREF(A, 0)
+			assert.True(t, A.Equals(tc.A))
+			assert.Equal(t, tc.pivots, pivots)
+			assertInDelta(t, tc.R, R, 1e-12)
+		})
+	}
+
+	// a larger tolerance treats small values as zero
+	_, pivots := RREF(New(2, 2, 1, 0, 0, 1e-6), 1e-3)
+	assert.Equal(t, []int{1}, pivots)
+}
+
+func TestSubspaces(t *testing.T) {
+	A := New(3, 4, 1, 2, 1, 1, 2, 4, 0, 6, 1, 2, 2, -1)
+
+	N := NullSpace(A, 0)
+	assertInDelta(t, New(4, 2, -2, -3, 1, 0, 0, 2, 0, 1), N, 1e-12)
+	assertInDelta(t, New(3, 2), A.Mul(N), 1e-12)
+
+	C := ColumnSpace(A, 0)
+	assertInDelta(t, New(3, 2, 1, 1, 2, 0, 1, 2), C, 0)
+
+	R := RowSpace(A, 0)
+	assertInDelta(t, New(4, 2, 1, 0, 2, 0, 0, 1, 3, -2), R, 1e-12)
+	// row space is orthogonal to the null space
+	assertInDelta(t, New(2, 2), R.Transpose().Mul(N), 1e-12)
+
+	L := LeftNullSpace(A, 0)
+	_, cols := L.Dims()
+	assert.Equal(t, 1, cols)
+	assertInDelta(t, New(1, 4), L.Transpose().Mul(A), 1e-12)
+
+	assert.Nil(t, NullSpace(Eye(3), 0))
+	assert.Nil(t, LeftNullSpace(Eye(3), 0))
+	assert.Nil(t, ColumnSpace(New(2, 2), 0))
+	assert.Nil(t, RowSpace(New(2, 2), 0))
+	assertInDelta(t, Eye(2), NullSpace(New(1, 2), 0), 0)
+}
@@ -0,0 +1,298 @@
+package mat
+
+import "sort"
+
+// COO builds sparse matrices in coordinate format. Elements can be added
+// in any order, duplicates are summed when converting to CSR or CSC.
+type COO struct {
+	rows, cols int
+	is, js     []int
+	vs         []float64
+}
+
+// NewCOO starts an empty sparse matrix of specified size. Panics if asked
+// to create a 0 size matrix.
+func NewCOO(rows, cols int) *COO {
+	if rows <= 0 || cols <= 0 {
+		panic(errorf(ErrShape, "invalid matrix size (%d x %d)", rows, cols))
+	}
+	return &COO{rows: rows, cols: cols}
+}
+
+// Add value at row/col. Indices are 1 based. Adding to the same position
+// more than once accumulates. Panics if indices exceed matrix size.
+func (c *COO) Add(row, col int, value float64) {
+	if row > c.rows || row <= 0 || col > c.cols || col <= 0 {
+		panic(errorf(ErrIndex, "invalid matrix indices: %d, %d", row, col))
+	}
+	c.is = append(c.is, row)
+	c.js = append(c.js, col)
+	c.vs = append(c.vs, value)
+}
+
+// ToCSR converts to compressed sparse row storage.
+func (c *COO) ToCSR() *CSR {
+	ptr, ind, data := compress(c.rows, c.is, c.js, c.vs)
+	return &CSR{rows: c.rows, cols: c.cols, ptr: ptr, ind: ind, data: data}
+}
+
+// ToCSC converts to compressed sparse column storage.
+func (c *COO) ToCSC() *CSC {
+	ptr, ind, data := compress(c.cols, c.js, c.is, c.vs)
+	return &CSC{rows: c.rows, cols: c.cols, ptr: ptr, ind: ind, data: data}
+}
+
+// CSR is a sparse matrix in compressed sparse row format. It is the
+// preferred storage for the iterative solvers, which walk rows.
+type CSR struct {
+	rows, cols int
+	// elements of row i are ind[ptr[i-1]:ptr[i]] (1 based column
+	// numbers, increasing) and data[ptr[i-1]:ptr[i]]
+	ptr  []int
+	ind  []int
+	data []float64
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (s *CSR) Dims() (int, int) {
+	return s.rows, s.cols
+}
+
+// NNZ returns the number of stored elements.
+func (s *CSR) NNZ() int {
+	return len(s.data)
+}
+
+// Get value at row/col. Panics if indices exceed matrix size.
+func (s *CSR) Get(row, col int) float64 {
+	if row > s.rows || row <= 0 || col > s.cols || col <= 0 {
+		panic(errorf(ErrIndex, "invalid matrix indices: %d, %d", row, col))
+	}
+	return lookup(s.ind[s.ptr[row-1]:s.ptr[row]], s.data[s.ptr[row-1]:s.ptr[row]], col)
+}
+
+// DoRowNonZero calls fn for every stored element in row. Panics if row is
+// invalid.
+func (s *CSR) DoRowNonZero(row int, fn func(col int, v float64)) {
+	if row > s.rows || row <= 0 {
+		panic(errorf(ErrIndex, "invalid row number: %d", row))
+	}
+	for k := s.ptr[row-1]; k < s.ptr[row]; k++ {
+		fn(s.ind[k], s.data[k])
+	}
+}
+
+// Mul multiplies the sparse matrix with a dense one, returning a dense
+// matrix. Panics if shapes don't match.
+func (s *CSR) Mul(other *M) *M {
+	if s.cols != other.rows {
+		panic(errorf(ErrShape, "can't multiply matrices of shapes %dx%d and %dx%d", s.rows, s.cols, other.rows, other.cols))
+	}
+	res := New(s.rows, other.cols)
+	for j := 1; j <= other.cols; j++ {
+		for i := 1; i <= s.rows; i++ {
+			var x float64
+			for k := s.ptr[i-1]; k < s.ptr[i]; k++ {
+				x += s.data[k] * other.Get(s.ind[k], j)
+			}
+			res.Set(i, j, x)
+		}
+	}
+	return res
+}
+
+// Transpose returns the transposed matrix in CSC format. The two share
+// memory since the storage layouts mirror each other.
+func (s *CSR) Transpose() *CSC {
+	return &CSC{rows: s.cols, cols: s.rows, ptr: s.ptr, ind: s.ind, data: s.data}
+}
+
+// ToCSC converts to compressed sparse column storage.
+func (s *CSR) ToCSC() *CSC {
+	is := make([]int, len(s.data))
+	for i := 1; i <= s.rows; i++ {
+		for k := s.ptr[i-1]; k < s.ptr[i]; k++ {
+			is[k] = i
+		}
+	}
+	ptr, ind, data := compress(s.cols, s.ind, is, s.data)
+	return &CSC{rows: s.rows, cols: s.cols, ptr: ptr, ind: ind, data: data}
+}
+
+// Dense returns a dense copy of the matrix.
+func (s *CSR) Dense() *M {
+	m := New(s.rows, s.cols)
+	for i := 1; i <= s.rows; i++ {
+		for k := s.ptr[i-1]; k < s.ptr[i]; k++ {
+			m.Set(i, s.ind[k], s.data[k])
+		}
+	}
+	return m
+}
+
+// CSC is a sparse matrix in compressed sparse column format.
+type CSC struct {
+	rows, cols int
+	// elements of column j are ind[ptr[j-1]:ptr[j]] (1 based row
+	// numbers, increasing) and data[ptr[j-1]:ptr[j]]
+	ptr  []int
+	ind  []int
+	data []float64
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (s *CSC) Dims() (int, int) {
+	return s.rows, s.cols
+}
+
+// NNZ returns the number of stored elements.
+func (s *CSC) NNZ() int {
+	return len(s.data)
+}
+
+// Get value at row/col. Panics if indices exceed matrix size.
+func (s *CSC) Get(row, col int) float64 {
+	if row > s.rows || row <= 0 || col > s.cols || col <= 0 {
+		panic(errorf(ErrIndex, "invalid matrix indices: %d, %d", row, col))
+	}
+	return lookup(s.ind[s.ptr[col-1]:s.ptr[col]], s.data[s.ptr[col-1]:s.ptr[col]], row)
+}
+
+// DoRowNonZero calls fn for every stored element in row. This needs a
+// search in every column, convert to CSR when rows are walked often.
+// Panics if row is invalid.
+func (s *CSC) DoRowNonZero(row int, fn func(col int, v float64)) {
+	if row > s.rows || row <= 0 {
+		panic(errorf(ErrIndex, "invalid row number: %d", row))
+	}
+	for j := 1; j <= s.cols; j++ {
+		ind := s.ind[s.ptr[j-1]:s.ptr[j]]
+		if k := sort.SearchInts(ind, row); k < len(ind) && ind[k] == row {
+			fn(j, s.data[s.ptr[j-1]+k])
+		}
+	}
+}
+
+// DoColNonZero calls fn for every stored element in col. Panics if col is
+// invalid.
+func (s *CSC) DoColNonZero(col int, fn func(row int, v float64)) {
+	if col > s.cols || col <= 0 {
+		panic(errorf(ErrIndex, "invalid column number: %d", col))
+	}
+	for k := s.ptr[col-1]; k < s.ptr[col]; k++ {
+		fn(s.ind[k], s.data[k])
+	}
+}
+
+// Mul multiplies the sparse matrix with a dense one, returning a dense
+// matrix. Panics if shapes don't match.
+func (s *CSC) Mul(other *M) *M {
+	if s.cols != other.rows {
+		panic(errorf(ErrShape, "can't multiply matrices of shapes %dx%d and %dx%d", s.rows, s.cols, other.rows, other.cols))
+	}
+	res := New(s.rows, other.cols)
+	for j := 1; j <= other.cols; j++ {
+		for c := 1; c <= s.cols; c++ {
+			x := other.Get(c, j)
+			if x == 0 {
+				continue
+			}
+			for k := s.ptr[c-1]; k < s.ptr[c]; k++ {
+				res.Set(s.ind[k], j, res.Get(s.ind[k], j)+s.data[k]*x)
+			}
+		}
+	}
+	return res
+}
+
+// Transpose returns the transposed matrix in CSR format. The two share
+// memory since the storage layouts mirror each other.
+func (s *CSC) Transpose() *CSR {
+	return &CSR{rows: s.cols, cols: s.rows, ptr: s.ptr, ind: s.ind, data: s.data}
+}
+
+// ToCSR converts to compressed sparse row storage.
+func (s *CSC) ToCSR() *CSR {
+	js := make([]int, len(s.data))
+	for j := 1; j <= s.cols; j++ {
+		for k := s.ptr[j-1]; k < s.ptr[j]; k++ {
+			js[k] = j
+		}
+	}
+	ptr, ind, data := compress(s.rows, s.ind, js, s.data)
+	return &CSR{rows: s.rows, cols: s.cols, ptr: ptr, ind: ind, data: data}
+}
+
+// Dense returns a dense copy of the matrix.
+func (s *CSC) Dense() *M {
+	m := New(s.rows, s.cols)
+	for j := 1; j <= s.cols; j++ {
+		for k := s.ptr[j-1]; k < s.ptr[j]; k++ {
+			m.Set(s.ind[k], j, s.data[k])
+		}
+	}
+	return m
+}
+
+// compress groups (major, minor, value) triplets by the 1 based major
+// index, sorts each group by minor index and sums duplicates. Returns the
+// group boundaries along with the minor indices and values.
+func compress(n int, major, minor []int, vs []float64) ([]int, []int, []float64) {
+	ptr := make([]int, n+1)
+	for _, i := range major {
+		ptr[i]++
+	}
+	for i := 1; i <= n; i++ {
+		ptr[i] += ptr[i-1]
+	}
+	// ptr[i] is now the end of group i, fill groups from their start
+	next := make([]int, n)
+	copy(next, ptr[:n])
+	ind := make([]int, len(vs))
+	data := make([]float64, len(vs))
+	for k, i := range major {
+		p := next[i-1]
+		ind[p], data[p] = minor[k], vs[k]
+		next[i-1]++
+	}
+	// sort every group and merge duplicates, compacting as we go
+	w := 0
+	start := 0
+	for i := 1; i <= n; i++ {
+		end := ptr[i]
+		sort.Sort(byIndex{ind[start:end], data[start:end]})
+		groupStart := w
+		for k := start; k < end; k++ {
+			if w > groupStart && ind[w-1] == ind[k] {
+				data[w-1] += data[k]
+				continue
+			}
+			ind[w], data[w] = ind[k], data[k]
+			w++
+		}
+		start = end
+		ptr[i] = w
+	}
+	return ptr, ind[:w], data[:w]
+}
+
+// lookup finds index i in sorted ind, returning the matching value or 0.
+func lookup(ind []int, data []float64, i int) float64 {
+	k := sort.SearchInts(ind, i)
+	if k < len(ind) && ind[k] == i {
+		return data[k]
+	}
+	return 0
+}
+
+type byIndex struct {
+	ind  []int
+	data []float64
+}
+
+func (b byIndex) Len() int           { return len(b.ind) }
+func (b byIndex) Less(i, j int) bool { return b.ind[i] < b.ind[j] }
+func (b byIndex) Swap(i, j int) {
+	b.ind[i], b.ind[j] = b.ind[j], b.ind[i]
+	b.data[i], b.data[j] = b.data[j], b.data[i]
+}
@@ -0,0 +1,92 @@
+package mat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCOOConversion(t *testing.T) {
+	c := NewCOO(3, 4)
+	c.Add(3, 1, 5)
+	c.Add(1, 2, 1)
+	c.Add(2, 4, 2)
+	c.Add(1, 2, 3) // duplicate, summed
+	c.Add(3, 3, 6)
+	dense := New(3, 4, 0, 4, 0, 0, 0, 0, 0, 2, 5, 0, 6, 0)
+	csr := c.ToCSR()
+	csc := c.ToCSC()
+	assert.Equal(t, 4, csr.NNZ())
+	assert.Equal(t, 4, csc.NNZ())
+	assert.True(t, dense.Equals(csr.Dense()))
+	assert.True(t, dense.Equals(csc.Dense()))
+	assert.True(t, dense.Equals(csr.ToCSC().Dense()))
+	assert.True(t, dense.Equals(csc.ToCSR().Dense()))
+	assert.True(t, dense.Transpose().Equals(csr.Transpose().Dense()))
+	for i := 1; i <= 3; i++ {
+		for j := 1; j <= 4; j++ {
+			assert.Equal(t, dense.Get(i, j), csr.Get(i, j))
+			assert.Equal(t, dense.Get(i, j), csc.Get(i, j))
+		}
+	}
+}
+
+func TestCOOInvalidIndices(t *testing.T) {
+	c := NewCOO(2, 2)
+	assert.Panics(t, func() { c.Add(3, 1, 1) })
+	assert.Panics(t, func() { NewCOO(0, 2) })
+}
+
+func TestSparseDoRowNonZero(t *testing.T) {
+	c := NewCOO(2, 3)
+	c.Add(2, 3, 1)
+	c.Add(2, 1, 2)
+	for _, s := range []Matrix{c.ToCSR(), c.ToCSC(), c.ToCSR().Dense()} {
+		var cols []int
+		var vals []float64
+		s.DoRowNonZero(2, func(j int, v float64) {
+			cols = append(cols, j)
+			vals = append(vals, v)
+		})
+		assert.Equal(t, []int{1, 3}, cols)
+		assert.Equal(t, []float64{2, 1}, vals)
+	}
+}
+
+func TestSparseMul(t *testing.T) {
+	c := NewCOO(3, 3)
+	c.Add(1, 1, 2)
+	c.Add(2, 3, -1)
+	c.Add(3, 2, 4)
+	dense := c.ToCSR().Dense()
+	x := New(3, 2, 1, 2, 3, 4, 5, 6)
+	assert.True(t, dense.Mul(x).Equals(c.ToCSR().Mul(x)))
+	assert.True(t, dense.Mul(x).Equals(c.ToCSC().Mul(x)))
+	y := Rand(2, 3)
+	assertInDelta(t, y.Mul(dense), y.Mul(c.ToCSR()), 1e-12)
+	assertInDelta(t, y.Mul(dense), y.Mul(c.ToCSC()), 1e-12)
+}
+
+func TestSparseIterativeSolvers(t *testing.T) {
+	// diagonally dominant tridiagonal system
+	n := 1000
+	c := NewCOO(n, n)
+	for i := 1; i <= n; i++ {
+		c.Add(i, i, 4)
+		if i > 1 {
+			c.Add(i, i-1, -1)
+		}
+		if i < n {
+			c.Add(i, i+1, -1)
+		}
+	}
+	A := c.ToCSR()
+	want := New(n, 1)
+	for i := 1; i <= n; i++ {
+		want.Set(i, 1, float64(i%7))
+	}
+	b := A.Mul(want)
+	x, _, err := SolveJacobi(A, b, New(n, 1), 1e-10, 200)
+	assert.NoError(t, err)
+	assertInDelta(t, want, x, 1e-8)
+}
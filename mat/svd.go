@@ -0,0 +1,199 @@
+package mat
+
+import "math"
+
+// machEps is the distance between 1 and the next larger float64.
+const machEps = 1.0 / (1 << 52)
+
+// svdMaxSweeps bounds the number of Jacobi sweeps in SVD. Convergence is
+// quadratic, so in practice fewer than 20 are ever needed.
+const svdMaxSweeps = 100
+
+// SVD computes the thin singular value decomposition A = U*diag(S)*V' of
+// an m x n matrix using one-sided Jacobi rotations. With k = min(m, n), U
+// is m x k, S is a k x 1 vector of singular values in descending order and
+// V is n x k; U and V have orthonormal columns. A is not mutated. Returns
+// error if the rotations do not converge.
+func SVD(A *M) (*M, *M, *M, error) {
+	if A.cols > A.rows {
+		// decompose the transpose instead, A' = V*S*U'
+		V, S, U, err := SVD(A.Transpose())
+		return U, S, V, err
+	}
+	n := A.cols
+	U := A.Clone()
+	V := Eye(n)
+	for sweep := 0; ; sweep++ {
+		if sweep == svdMaxSweeps {
+			return nil, nil, nil, errorf(ErrNotConverged, "jacobi sweep limit exceeded")
+		}
+		rotated := false
+		for p := 1; p < n; p++ {
+			for q := p + 1; q <= n; q++ {
+				// rotate columns p and q until they are orthogonal
+				var alpha, beta, gamma float64
+				for i := 1; i <= U.rows; i++ {
+					alpha += U.Get(i, p) * U.Get(i, p)
+					beta += U.Get(i, q) * U.Get(i, q)
+					gamma += U.Get(i, p) * U.Get(i, q)
+				}
+				if math.Abs(gamma) <= machEps*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+				zeta := (beta - alpha) / (2 * gamma)
+				t := 1 / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				if zeta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+				rotateCols(U, p, q, c, s)
+				rotateCols(V, p, q, c, s)
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+	// singular values are the column norms, normalizing gives U
+	S := New(n, 1)
+	for j := 1; j <= n; j++ {
+		norm := colLength(U, j)
+		S.Set(j, 1, norm)
+		if norm == 0 {
+			continue
+		}
+		for i := 1; i <= U.rows; i++ {
+			U.Set(i, j, U.Get(i, j)/norm)
+		}
+	}
+	for i := 1; i < n; i++ {
+		max := i
+		for j := i + 1; j <= n; j++ {
+			if S.Get(j, 1) > S.Get(max, 1) {
+				max = j
+			}
+		}
+		if max != i {
+			S.SwapRows(i, max)
+			U.SwapCols(i, max)
+			V.SwapCols(i, max)
+		}
+	}
+	for j := 1; j <= n; j++ {
+		if S.Get(j, 1) == 0 {
+			completeBasis(U, j)
+		}
+	}
+	return U, S, V, nil
+}
+
+// SVDRank returns the numerical rank of A, i.e. the number of singular
+// values larger than tol. A tol <= 0 selects the usual default of
+// max(m, n) * S(1) * machine epsilon.
+func SVDRank(A *M, tol float64) (int, error) {
+	_, S, _, err := SVD(A)
+	if err != nil {
+		return 0, err
+	}
+	tol = svdTolerance(A, S, tol)
+	r := 0
+	for i := 1; i <= S.rows && S.Get(i, 1) > tol; i++ {
+		r++
+	}
+	return r, nil
+}
+
+// PseudoInverse computes the Moore-Penrose pseudo-inverse of A as
+// V*diag(1/S)*U'. Singular values not larger than tol are treated as
+// zero; tol <= 0 selects the same default as SVDRank.
+func PseudoInverse(A *M, tol float64) (*M, error) {
+	U, S, V, err := SVD(A)
+	if err != nil {
+		return nil, err
+	}
+	tol = svdTolerance(A, S, tol)
+	// scale columns of V by 1/S, then multiply by U'
+	for j := 1; j <= V.cols; j++ {
+		f := 0.0
+		if S.Get(j, 1) > tol {
+			f = 1 / S.Get(j, 1)
+		}
+		for i := 1; i <= V.rows; i++ {
+			V.Set(i, j, V.Get(i, j)*f)
+		}
+	}
+	return V.Mul(U.Transpose()), nil
+}
+
+// Cond2 returns the 2-norm condition number of A, the ratio between its
+// largest and smallest singular values. Rank deficient matrices have an
+// infinite condition number.
+func Cond2(A *M) (float64, error) {
+	_, S, _, err := SVD(A)
+	if err != nil {
+		return 0, err
+	}
+	min := S.Get(S.rows, 1)
+	if min == 0 {
+		return math.Inf(1), nil
+	}
+	return S.Get(1, 1) / min, nil
+}
+
+func svdTolerance(A, S *M, tol float64) float64 {
+	if tol > 0 {
+		return tol
+	}
+	dim := A.rows
+	if A.cols > dim {
+		dim = A.cols
+	}
+	return float64(dim) * S.Get(1, 1) * machEps
+}
+
+// rotateCols applies a plane rotation to columns p and q of A, in place.
+func rotateCols(A *M, p, q int, c, s float64) {
+	for i := 1; i <= A.rows; i++ {
+		ap, aq := A.Get(i, p), A.Get(i, q)
+		A.Set(i, p, c*ap-s*aq)
+		A.Set(i, q, s*ap+c*aq)
+	}
+}
+
+// completeBasis replaces column col of U, which is zero, with a unit
+// vector orthogonal to every other column. It tries the standard basis
+// vectors in turn and keeps the first one with a large enough component
+// outside the span of the others.
+func completeBasis(U *M, col int) {
+	v := New(U.rows, 1)
+	for k := 1; k <= U.rows; k++ {
+		for i := 1; i <= U.rows; i++ {
+			v.Set(i, 1, 0)
+		}
+		v.Set(k, 1, 1)
+		// project twice to keep orthogonality to machine precision
+		for pass := 0; pass < 2; pass++ {
+			for j := 1; j <= U.cols; j++ {
+				if j == col {
+					continue
+				}
+				var dp float64
+				for i := 1; i <= U.rows; i++ {
+					dp += U.Get(i, j) * v.Get(i, 1)
+				}
+				for i := 1; i <= U.rows; i++ {
+					v.Set(i, 1, v.Get(i, 1)-dp*U.Get(i, j))
+				}
+			}
+		}
+		norm := colLength(v, 1)
+		if norm > 0.5 {
+			for i := 1; i <= U.rows; i++ {
+				U.Set(i, col, v.Get(i, 1)/norm)
+			}
+			return
+		}
+	}
+}
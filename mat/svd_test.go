@@ -0,0 +1,74 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSVD(t *testing.T) {
+	tcs := []*M{
+		New(3, 2, 3, 2, 2, 3, 2, -2),
+		New(2, 3, 3, 2, 2, 2, 3, -2),
+		New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9),
+		New(3, 3, 0, 0, 0, 0, 0, 0, 0, 0, 1),
+		Rand(6, 4),
+	}
+	for i, A := range tcs {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			U, S, V, err := SVD(A)
+			assert.NoError(t, err)
+			k := S.rows
+			for j := 2; j <= k; j++ {
+				assert.True(t, S.Get(j-1, 1) >= S.Get(j, 1))
+			}
+			assertInDelta(t, Eye(k), U.Transpose().Mul(U), 1e-9)
+			assertInDelta(t, Eye(k), V.Transpose().Mul(V), 1e-9)
+			US := U.Clone()
+			for j := 1; j <= k; j++ {
+				for r := 1; r <= US.rows; r++ {
+					US.Set(r, j, US.Get(r, j)*S.Get(j, 1))
+				}
+			}
+			assertInDelta(t, A, US.Mul(V.Transpose()), 1e-9)
+		})
+	}
+}
+
+func TestSVDKnownValues(t *testing.T) {
+	_, S, _, err := SVD(New(2, 3, 3, 2, 2, 2, 3, -2))
+	assert.NoError(t, err)
+	assertInDelta(t, Vec(5, 3), S, 1e-9)
+}
+
+func TestSVDRank(t *testing.T) {
+	r, err := SVDRank(New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r)
+	r, err = SVDRank(New(2, 4, 1, 2, 3, 4, 2, 4, 6, 8), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, r)
+}
+
+func TestPseudoInverse(t *testing.T) {
+	A := New(3, 2, 1, 2, 3, 4, 5, 6)
+	P, err := PseudoInverse(A, 0)
+	assert.NoError(t, err)
+	assertInDelta(t, Eye(2), P.Mul(A), 1e-9)
+	// rank deficient case still satisfies A*P*A = A
+	A = New(3, 3, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	P, err = PseudoInverse(A, 0)
+	assert.NoError(t, err)
+	assertInDelta(t, A, A.Mul(P).Mul(A), 1e-9)
+}
+
+func TestCond2(t *testing.T) {
+	c, err := Cond2(New(2, 2, 10, 0, 0, 1))
+	assert.NoError(t, err)
+	assert.InDelta(t, 10, c, 1e-9)
+	c, err = Cond2(New(2, 2, 1, 2, 2, 4))
+	assert.NoError(t, err)
+	assert.True(t, math.IsInf(c, 1) || c > 1e15)
+}